@@ -0,0 +1,126 @@
+// Package abnf contains the core rules of ABNF, as defined in appendix B.1 of
+// RFC 5234. These rules are used by almost every protocol grammar.
+package abnf
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+var (
+	// ALPHA = %x41-5A / %x61-7A ; A-Z / a-z
+	ALPHA = parser.NewCharSet(parser.CheckRuneRange(0x41, 0x5A), parser.CheckRuneRange(0x61, 0x7A))
+	// BIT = "0" / "1"
+	BIT = parser.CharSetOf('0', '1')
+	// CHAR = %x01-7F ; any 7-bit US-ASCII character, excluding NUL
+	CHAR = parser.CheckRuneRange(0x01, 0x7F)
+	// CR = %x0D ; carriage return
+	CR = parser.CheckRune(0x0D)
+	// CRLF = CR LF ; Internet standard newline
+	CRLF = parser.CheckString("\r\n")
+	// CTL = %x00-1F / %x7F ; controls
+	CTL = parser.NewCharSet(parser.CheckRuneRange(0x00, 0x1F), parser.CheckRuneRange(0x7F, 0x7F))
+	// DIGIT = %x30-39 ; 0-9
+	DIGIT = parser.CheckRuneRange(0x30, 0x39)
+	// DQUOTE = %x22 ; " (Double Quote)
+	DQUOTE = parser.CheckRune(0x22)
+	// HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+	//
+	// Strings are case-insensitive in ABNF, so lower case letters match too.
+	HEXDIG = parser.NewCharSet(DIGIT, parser.CheckRuneRange('A', 'F'), parser.CheckRuneRange('a', 'f'))
+	// HTAB = %x09 ; horizontal tab
+	HTAB = parser.CheckRune(0x09)
+	// LF = %x0A ; linefeed
+	LF = parser.CheckRune(0x0A)
+	// LWSP = *(WSP / CRLF WSP) ; linear-white-space
+	LWSP parser.AnonymousClass = func(p *parser.Parser) (*parser.Cursor, bool) {
+		last, _ := p.Check(op.MinZero(op.Or{WSP, op.And{CRLF, WSP}}))
+		return last, true
+	}
+	// OCTET = %x00-FF ; 8 bits of data
+	//
+	// Use DecodeOctet to decode the data byte per byte, otherwise the runes
+	// U+0000 to U+00FF match.
+	OCTET = parser.CheckRuneRange(0x00, 0xFF)
+	// SP = %x20
+	SP = parser.CheckRune(0x20)
+	// VCHAR = %x21-7E ; visible (printing) characters
+	VCHAR = parser.CheckRuneRange(0x21, 0x7E)
+	// WSP = SP / HTAB ; white space
+	WSP = parser.CharSetOf(0x20, 0x09)
+)
+
+// DecodeOctet decodes a single byte, it can be passed to the DecodeRune method
+// of a parser to parse binary data.
+func DecodeOctet(p []byte) (rune, int) {
+	if len(p) == 0 {
+		return 0, 0
+	}
+	return rune(p[0]), 1
+}
+
+// Core contains all the core rules by name.
+var Core = map[string]parser.AnonymousClass{
+	"ALPHA":  ALPHA.Check,
+	"BIT":    BIT.Check,
+	"CHAR":   CHAR.Check,
+	"CR":     CR.Check,
+	"CRLF":   CRLF.Check,
+	"CTL":    CTL.Check,
+	"DIGIT":  DIGIT.Check,
+	"DQUOTE": DQUOTE.Check,
+	"HEXDIG": HEXDIG.Check,
+	"HTAB":   HTAB.Check,
+	"LF":     LF.Check,
+	"LWSP":   LWSP,
+	"OCTET":  OCTET.Check,
+	"SP":     SP.Check,
+	"VCHAR":  VCHAR.Check,
+	"WSP":    WSP.Check,
+}
+
+// TypeStrings contains the type strings of the nodes produced by the rules
+// returned by Node. The type of a node is the index of its rule.
+var TypeStrings = []string{
+	"UNKNOWN",
+
+	"ALPHA",
+	"BIT",
+	"CHAR",
+	"CR",
+	"CRLF",
+	"CTL",
+	"DIGIT",
+	"DQUOTE",
+	"HEXDIG",
+	"HTAB",
+	"LF",
+	"LWSP",
+	"OCTET",
+	"SP",
+	"VCHAR",
+	"WSP",
+}
+
+// Node returns a rule that captures the core rule with the given name in a
+// node. Returns nil if there is no core rule with the name.
+func Node(name string) ast.ParseNode {
+	class, ok := Core[name]
+	if !ok {
+		return nil
+	}
+	var typ int
+	for i, s := range TypeStrings {
+		if s == name {
+			typ = i
+		}
+	}
+	return func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(ast.Capture{
+			Type:        typ,
+			TypeStrings: TypeStrings,
+			Value:       class,
+		})
+	}
+}
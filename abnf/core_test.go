@@ -0,0 +1,146 @@
+package abnf_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/abnf"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+// The single rune core rules as ranges, copied from appendix B.1 of RFC 5234.
+var ranges = map[string][][2]rune{
+	"ALPHA":  {{0x41, 0x5A}, {0x61, 0x7A}},
+	"BIT":    {{'0', '0'}, {'1', '1'}},
+	"CHAR":   {{0x01, 0x7F}},
+	"CR":     {{0x0D, 0x0D}},
+	"CTL":    {{0x00, 0x1F}, {0x7F, 0x7F}},
+	"DIGIT":  {{0x30, 0x39}},
+	"DQUOTE": {{0x22, 0x22}},
+	"HEXDIG": {{0x30, 0x39}, {'A', 'F'}, {'a', 'f'}},
+	"HTAB":   {{0x09, 0x09}},
+	"LF":     {{0x0A, 0x0A}},
+	"OCTET":  {{0x00, 0xFF}},
+	"SP":     {{0x20, 0x20}},
+	"VCHAR":  {{0x21, 0x7E}},
+	"WSP":    {{0x20, 0x20}, {0x09, 0x09}},
+}
+
+func TestCore_runes(t *testing.T) {
+	runes := []rune{0x100, 0x17F, 0x2028, 0xFEFF, 0xFFFD, 0x1F600, 0x10FFFF}
+	for r := rune(0x00); r <= 0xFF; r++ {
+		runes = append(runes, r)
+	}
+	for name, rs := range ranges {
+		class := abnf.Core[name]
+		for _, r := range runes {
+			var in bool
+			for _, rng := range rs {
+				if rng[0] <= r && r <= rng[1] {
+					in = true
+				}
+			}
+			p, _ := parser.New([]byte(string(r)))
+			last, err := p.Expect(class)
+			if match := err == nil; match != in {
+				t.Errorf("%s: %U: expected %t, got %t", name, r, in, match)
+			}
+			if err == nil && last.Rune != r {
+				t.Errorf("%s: %U: got %U", name, r, last.Rune)
+			}
+		}
+		// No rule matches the end of the data.
+		p, _ := parser.New([]byte("a"))
+		p.Next()
+		if _, err := p.Expect(class); err == nil {
+			t.Errorf("%s: matched the end of the data", name)
+		}
+	}
+}
+
+func TestCore_sequences(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+		// The number of bytes consumed, -1 if it should fail.
+		n int
+	}{
+		{"CRLF", "\r\n", 2},
+		{"CRLF", "\r\nx", 2},
+		{"CRLF", "\n", -1},
+		{"CRLF", "\r", -1},
+		{"CRLF", "\n\r", -1},
+		{"LWSP", "x", 0},
+		{"LWSP", " \t x", 3},
+		{"LWSP", "\r\n x", 3},
+		{"LWSP", " \r\n\t\r\n x", 7},
+		{"LWSP", " \r\nx", 1},    // CRLF must be followed by WSP.
+		{"LWSP", "\r\n\r\n ", 0}, // Empty lines are not allowed.
+	} {
+		p, _ := parser.New([]byte(test.input))
+		_, err := p.Expect(abnf.Core[test.name])
+		if test.n < 0 {
+			if err == nil {
+				t.Errorf("%s: %q: expected an error", test.name, test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %q: %v", test.name, test.input, err)
+			continue
+		}
+		if n := p.Mark().Offset(); n != test.n {
+			t.Errorf("%s: %q: expected %d bytes, got %d", test.name, test.input, test.n, n)
+		}
+	}
+}
+
+func TestTypeStrings(t *testing.T) {
+	if len(abnf.TypeStrings) != len(abnf.Core)+1 {
+		t.Fatalf("expected %d type strings, got %d", len(abnf.Core)+1, len(abnf.TypeStrings))
+	}
+	for _, name := range abnf.TypeStrings[1:] {
+		if abnf.Node(name) == nil {
+			t.Errorf("%s: no rule", name)
+		}
+	}
+	if abnf.Node("UNKNOWN") != nil {
+		t.Error("UNKNOWN: expected no rule")
+	}
+}
+
+func Example() {
+	p, _ := parser.New([]byte("0x1F"))
+	fmt.Println(p.Expect(op.And{'0', 'x', op.MinOne(abnf.HEXDIG)}))
+	// Output:
+	// U+0046: F <nil>
+}
+
+func Example_classes() {
+	// The rules with multiple ranges are sets, they can be combined.
+	fmt.Println(abnf.ALPHA.Union(abnf.HEXDIG).Union(parser.CharSetOf('_')))
+	fmt.Println(abnf.WSP.Contains('\t'), abnf.CTL.Contains(0x7F))
+	// Output:
+	// [0-9A-Z_a-z]
+	// true true
+}
+
+func ExampleNode() {
+	p, _ := ast.New([]byte("GET / HTTP/1.1\r\n"))
+	fmt.Println(p.Expect(op.And{
+		op.MinOne(abnf.Node("ALPHA")), abnf.SP, '/', abnf.SP,
+		"HTTP/", abnf.Node("DIGIT"), '.', abnf.Node("DIGIT"),
+		abnf.Node("CRLF"),
+	}))
+	// Output:
+	// ["UNKNOWN",[["ALPHA","G"],["ALPHA","E"],["ALPHA","T"],["DIGIT","1"],["DIGIT","1"],["CRLF","\r\n"]]] <nil>
+}
+
+func ExampleDecodeOctet() {
+	p, _ := parser.New([]byte{0x00, 0xC3, 0xA9, 0xFF})
+	p.DecodeRune(abnf.DecodeOctet)
+	fmt.Println(p.Expect(op.Repeat(4, abnf.OCTET)))
+	// Output:
+	// U+00FF: ÿ <nil>
+}
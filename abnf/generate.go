@@ -0,0 +1,163 @@
+package abnf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Generate parses the given ABNF grammar and generates the Go source of a
+// package with the given name, containing an ast.ParseNode for every rule. The
+// names of the functions are the names of the rules in camel case, e.g.
+// HTTPVersion for HTTP-version. References to the core rules use the classes of
+// this package.
+func Generate(data []byte, pkg string) ([]byte, error) {
+	n, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := importRules(n, data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := generator{
+		names:   make(map[string]string),
+		imports: map[string]bool{"github.com/di-wu/parser/ast": true},
+	}
+	goNames := make(map[string]string)
+	for _, r := range rules {
+		name := goName(r.name)
+		if other, ok := goNames[name]; ok {
+			return nil, fmt.Errorf("abnf: rules %s and %s have the same Go name %s", other, r.name, name)
+		}
+		goNames[name] = r.name
+		g.names[r.name] = name
+	}
+
+	var body bytes.Buffer
+	for _, r := range rules {
+		fmt.Fprintf(&body, "\nfunc %s(p *ast.Parser) (*ast.Node, error) {\n", g.names[r.name])
+		fmt.Fprintf(&body, "return p.Expect(\nast.Capture{\nType: %sType,\nTypeStrings: NodeTypes,\nValue: ", g.names[r.name])
+		g.write(&body, r.value)
+		body.WriteString(",\n},\n)\n}\n")
+	}
+	if len(g.undefined) != 0 {
+		var names []string
+		for name := range g.undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &grammar.UndefinedError{Names: names}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by abnf.Generate. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	var imports []string
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+	src.WriteString("\n// Node Types\nconst (\nUnknown = iota\n\n")
+	for i, r := range rules {
+		fmt.Fprintf(&src, "%sType // %03d\n", g.names[r.name], i+1)
+	}
+	src.WriteString(")\n\nvar NodeTypes = []string{\n\"UNKNOWN\",\n\n")
+	for _, r := range rules {
+		fmt.Fprintf(&src, "%q,\n", r.name)
+	}
+	src.WriteString("}\n")
+	return format.Source(src.Bytes())
+}
+
+// goName converts the name of a rule to an exported Go identifier.
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+type generator struct {
+	// names maps the names of the rules to their Go names.
+	names map[string]string
+	// imports contains the packages that are used.
+	imports map[string]bool
+	// undefined contains the references to rules that do not exist.
+	undefined map[string]bool
+}
+
+// write writes the Go source of the (imported) value.
+func (g *generator) write(b *bytes.Buffer, i interface{}) {
+	switch v := i.(type) {
+	case op.And:
+		g.writeAll(b, "op.And", v)
+	case op.Or:
+		g.writeAll(b, "op.Or", v)
+	case op.Range:
+		g.imports["github.com/di-wu/parser/op"] = true
+		switch {
+		case v.Min == 0 && v.Max == -1:
+			b.WriteString("op.MinZero(\n")
+		case v.Min == 1 && v.Max == -1:
+			b.WriteString("op.MinOne(\n")
+		case v.Min == 0 && v.Max == 1:
+			b.WriteString("op.Optional(\n")
+		case v.Max == -1:
+			fmt.Fprintf(b, "op.Min(%d,\n", v.Min)
+		case v.Min == v.Max:
+			fmt.Fprintf(b, "op.Repeat(%d,\n", v.Min)
+		default:
+			fmt.Fprintf(b, "op.MinMax(%d, %d,\n", v.Min, v.Max)
+		}
+		g.write(b, v.Value)
+		b.WriteString(",\n)")
+	case grammar.Ref:
+		name, ok := g.names[v.Name]
+		if !ok {
+			if g.undefined == nil {
+				g.undefined = make(map[string]bool)
+			}
+			g.undefined[v.Name] = true
+		}
+		b.WriteString(name)
+	case core:
+		g.imports["github.com/di-wu/parser/abnf"] = true
+		fmt.Fprintf(b, "abnf.%s", v)
+	case stringCI:
+		g.imports["github.com/di-wu/parser"] = true
+		fmt.Fprintf(b, "parser.CheckStringCI(%q)", string(v))
+	case runeRange:
+		g.imports["github.com/di-wu/parser"] = true
+		fmt.Fprintf(b, "parser.CheckRuneRange(0x%02X, 0x%02X)", v.min, v.max)
+	case rune:
+		b.WriteString(strconv.QuoteRune(v))
+	case string:
+		b.WriteString(strconv.Quote(v))
+	}
+}
+
+func (g *generator) writeAll(b *bytes.Buffer, typ string, values []interface{}) {
+	g.imports["github.com/di-wu/parser/op"] = true
+	b.WriteString(typ + "{\n")
+	for _, v := range values {
+		g.write(b, v)
+		b.WriteString(",\n")
+	}
+	b.WriteString("}")
+}
@@ -0,0 +1,233 @@
+package abnf
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// Node Types
+const (
+	Unknown = iota
+
+	// ABNF (RFC 5234, RFC 7405)
+	RuleListType      // 001
+	RuleType          // 002
+	RuleNameType      // 003
+	DefinedAsType     // 004
+	AlternationType   // 005
+	ConcatenationType // 006
+	RepetitionType    // 007
+	RepeatType        // 008
+	GroupType         // 009
+	OptionType        // 010
+	CharValType       // 011
+	NumValType        // 012
+	ProseValType      // 013
+)
+
+var NodeTypes = []string{
+	"UNKNOWN",
+
+	// ABNF (RFC 5234, RFC 7405)
+	"RuleList",
+	"Rule",
+	"RuleName",
+	"DefinedAs",
+	"Alternation",
+	"Concatenation",
+	"Repetition",
+	"Repeat",
+	"Group",
+	"Option",
+	"CharVal",
+	"NumVal",
+	"ProseVal",
+}
+
+// Parse parses the given ABNF grammar. Lines may end with a CRLF or a single LF,
+// and comments may contain any character.
+func Parse(data []byte) (*ast.Node, error) {
+	return ast.Parse(data, RuleList)
+}
+
+// RuleList = 1*( rule / (*c-wsp c-nl) )
+func RuleList(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        RuleListType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			op.MinZero(op.Or{
+				Rule,
+				op.And{op.MinZero(cWSP), cNL},
+			}),
+			op.MinZero(WSP),
+			parser.EOD,
+		},
+	})
+}
+
+// Rule = rulename defined-as elements c-nl
+func Rule(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        RuleType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			RuleName,
+			op.MinZero(cWSP), DefinedAs, op.MinZero(cWSP),
+			Alternation, op.MinZero(cWSP),
+			op.Or{cNL, parser.EOD},
+		},
+	})
+}
+
+// RuleName = ALPHA *(ALPHA / DIGIT / "-")
+func RuleName(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        RuleNameType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{ALPHA, op.MinZero(op.Or{ALPHA, DIGIT, '-'})},
+	})
+}
+
+// DefinedAs = "=" / "=/"
+func DefinedAs(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        DefinedAsType,
+		TypeStrings: NodeTypes,
+		Value:       op.Or{"=/", '='},
+	})
+}
+
+// Alternation = concatenation *(*c-wsp "/" *c-wsp concatenation)
+func Alternation(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        AlternationType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			Concatenation,
+			op.MinZero(op.And{op.MinZero(cWSP), '/', op.MinZero(cWSP), Concatenation}),
+		},
+	})
+}
+
+// Concatenation = repetition *(1*c-wsp repetition)
+func Concatenation(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ConcatenationType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Repetition, op.MinZero(op.And{op.MinOne(cWSP), Repetition})},
+	})
+}
+
+// Repetition = [repeat] element
+func Repetition(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        RepetitionType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			op.Optional(Repeat),
+			op.Or{RuleName, Group, Option, CharVal, NumVal, ProseVal},
+		},
+	})
+}
+
+// Repeat = 1*DIGIT / (*DIGIT "*" *DIGIT)
+func Repeat(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        RepeatType,
+		TypeStrings: NodeTypes,
+		Value: op.Or{
+			op.And{op.MinZero(DIGIT), '*', op.MinZero(DIGIT)},
+			op.MinOne(DIGIT),
+		},
+	})
+}
+
+// Group = "(" *c-wsp alternation *c-wsp ")"
+func Group(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        GroupType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{'(', op.MinZero(cWSP), Alternation, op.MinZero(cWSP), ')'},
+	})
+}
+
+// Option = "[" *c-wsp alternation *c-wsp "]"
+func Option(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        OptionType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{'[', op.MinZero(cWSP), Alternation, op.MinZero(cWSP), ']'},
+	})
+}
+
+// CharVal = ["%s" / "%i"] DQUOTE *(%x20-21 / %x23-7E) DQUOTE
+func CharVal(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        CharValType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			op.Optional(op.Or{parser.CheckStringCI("%s"), parser.CheckStringCI("%i")}),
+			DQUOTE,
+			op.MinZero(op.Or{parser.CheckRuneRange(0x20, 0x21), parser.CheckRuneRange(0x23, 0x7E)}),
+			DQUOTE,
+		},
+	})
+}
+
+// NumVal = "%" (bin-val / dec-val / hex-val)
+func NumVal(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        NumValType,
+		TypeStrings: NodeTypes,
+		Value: op.And{'%', op.Or{
+			numVal('b', BIT),
+			numVal('d', DIGIT),
+			numVal('x', HEXDIG),
+		}},
+	})
+}
+
+// numVal = base 1*digit [ 1*("." 1*digit) / ("-" 1*digit) ]
+func numVal(base rune, digit interface{}) op.And {
+	return op.And{
+		parser.CheckRuneCI(base),
+		op.MinOne(digit),
+		op.Optional(op.Or{
+			op.MinOne(op.And{'.', op.MinOne(digit)}),
+			op.And{'-', op.MinOne(digit)},
+		}),
+	}
+}
+
+// ProseVal = "<" *(%x20-3D / %x3F-7E) ">"
+func ProseVal(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ProseValType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			'<',
+			op.MinZero(op.Or{parser.CheckRuneRange(0x20, 0x3D), parser.CheckRuneRange(0x3F, 0x7E)}),
+			'>',
+		},
+	})
+}
+
+var (
+	// newline = CRLF / LF
+	newline = op.Or{CRLF, LF}
+	// c-wsp = WSP / (c-nl WSP)
+	cWSP = op.Or{WSP, op.And{cNL, WSP}}
+	// c-nl = comment / newline
+	cNL = op.Or{comment, newline}
+	// comment = ";" *(any - newline) (newline / EOD)
+	comment = op.And{
+		';',
+		op.MinZero(op.And{
+			op.Not{Value: newline},
+			parser.AnyRune{},
+		}),
+		op.Or{newline, parser.EOD},
+	}
+)
@@ -0,0 +1,277 @@
+package abnf
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Compile parses the given ABNF grammar and converts it to a grammar that can
+// be used to parse data directly. Every rule produces a node. References to the
+// core rules are resolved, unless the grammar (re)defines them.
+//
+// Strings are case-insensitive (parser.CheckStringCI), unless they are prefixed
+// with %s. Prose values (<...>) can not be compiled. Alternatives are tried in
+// order and the first one that matches wins, so ABNF that relies on ambiguity
+// (e.g. 1*DIGIT / *DIGIT "*" *DIGIT) needs its alternatives reordered.
+func Compile(data []byte) (*grammar.Grammar, error) {
+	n, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return CompileNode(n, data)
+}
+
+// CompileNode converts the given (parsed) ABNF grammar to a grammar. The data
+// is used to report the positions of errors.
+func CompileNode(n *ast.Node, data []byte) (*grammar.Grammar, error) {
+	rules, err := importRules(n, data)
+	if err != nil {
+		return nil, err
+	}
+	g := grammar.New()
+	for _, r := range rules {
+		if err := g.Add(&grammar.Rule{
+			Name:    r.name,
+			Capture: true,
+			Value:   grammar.Map(r.value, compile),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.Check(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// importError returns an error at the start of the given node.
+func importError(n *ast.Node, data []byte, format string, a ...interface{}) *grammar.ImportError {
+	return grammar.NewImportError("abnf", data, n.Start, format, a...)
+}
+
+// rule is an imported rule, the value is not yet compiled.
+type rule struct {
+	name  string
+	value interface{}
+}
+
+// The values that can not be represented by op values before they get compiled
+// (or generated).
+type (
+	// stringCI is a case-insensitive string.
+	stringCI string
+	// runeRange is a range of runes, inclusive.
+	runeRange struct {
+		min, max rune
+	}
+	// core is a reference to a core rule.
+	core string
+)
+
+// compile converts the values that do not have an op representation.
+func compile(i interface{}) interface{} {
+	switch v := i.(type) {
+	case stringCI:
+		return parser.CheckStringCI(string(v))
+	case runeRange:
+		return parser.CheckRuneRange(v.min, v.max)
+	case core:
+		return Core[string(v)]
+	}
+	return i
+}
+
+// importRules converts the rules of the given grammar, incremental alternatives
+// are merged with the rule they belong to. Rule names are case-insensitive, the
+// name of the first definition is used.
+func importRules(n *ast.Node, data []byte) ([]*rule, error) {
+	var (
+		names   = make(map[string]string)
+		defined = make(map[string]*ast.Node)
+	)
+	for _, r := range n.Children() {
+		children := r.Children()
+		name := strings.ToLower(children[0].Value)
+		if _, ok := names[name]; !ok {
+			names[name] = children[0].Value
+		}
+		if children[1].Value == "=" {
+			if _, ok := defined[name]; ok {
+				return nil, importError(r, data, "rule %s is already defined", children[0].Value)
+			}
+			defined[name] = r
+		}
+	}
+
+	i := importer{data: data, names: names}
+	var (
+		rules []*rule
+		index = make(map[string]int)
+	)
+	for _, r := range n.Children() {
+		children := r.Children()
+		name := strings.ToLower(children[0].Value)
+		if _, ok := defined[name]; !ok {
+			return nil, importError(r, data, "incremental alternatives for undefined rule %s", children[0].Value)
+		}
+		value, err := i.value(children[2])
+		if err != nil {
+			return nil, err
+		}
+		j, ok := index[name]
+		if !ok {
+			index[name] = len(rules)
+			rules = append(rules, &rule{
+				name:  names[name],
+				value: value,
+			})
+			continue
+		}
+		// Add the alternatives to the existing rule (=/).
+		or, ok := rules[j].value.(op.Or)
+		if !ok {
+			or = op.Or{rules[j].value}
+		}
+		if alt, ok := value.(op.Or); ok {
+			or = append(or, alt...)
+		} else {
+			or = append(or, value)
+		}
+		rules[j].value = or
+	}
+	return rules, nil
+}
+
+type importer struct {
+	data []byte
+	// names maps the lower cased names of all the rules to their names.
+	names map[string]string
+}
+
+func (i importer) value(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case AlternationType, ConcatenationType:
+		var values []interface{}
+		for _, c := range n.Children() {
+			v, err := i.value(c)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == AlternationType {
+			return op.Or(values), nil
+		}
+		return op.And(values), nil
+	case RepetitionType:
+		children := n.Children()
+		v, err := i.value(children[len(children)-1])
+		if err != nil || len(children) == 1 {
+			return v, err
+		}
+		return i.repeat(children[0], v)
+	case GroupType:
+		return i.value(n.FirstChild)
+	case OptionType:
+		v, err := i.value(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		return op.Optional(v), nil
+	case RuleNameType:
+		name := strings.ToLower(n.Value)
+		if name, ok := i.names[name]; ok {
+			return grammar.Ref{Name: name}, nil
+		}
+		if _, ok := Core[strings.ToUpper(name)]; ok {
+			return core(strings.ToUpper(name)), nil
+		}
+		return grammar.Ref{Name: n.Value}, nil
+	case CharValType:
+		return charVal(n.Value), nil
+	case NumValType:
+		return i.numVal(n)
+	case ProseValType:
+		return nil, importError(n, i.data, "prose value %s can not be imported", n.Value)
+	default:
+		return nil, importError(n, i.data, "unexpected node %s", n.TypeString())
+	}
+}
+
+// repeat applies the given repeat (n*m) to the value.
+func (i importer) repeat(n *ast.Node, v interface{}) (interface{}, error) {
+	bounds := strings.SplitN(n.Value, "*", 2)
+	min, max := 0, -1
+	if bounds[0] != "" {
+		var err error
+		if min, err = strconv.Atoi(bounds[0]); err != nil {
+			return nil, importError(n, i.data, "invalid repeat %s", n.Value)
+		}
+	}
+	if len(bounds) == 1 {
+		max = min
+	} else if bounds[1] != "" {
+		var err error
+		if max, err = strconv.Atoi(bounds[1]); err != nil || max < min {
+			return nil, importError(n, i.data, "invalid repeat %s", n.Value)
+		}
+	}
+	if max == 0 {
+		return nil, importError(n, i.data, "repeat %s never matches", n.Value)
+	}
+	return op.MinMax(min, max, v), nil
+}
+
+// charVal converts a (quoted) string, optionally prefixed with %s or %i.
+func charVal(s string) interface{} {
+	sensitive := strings.HasPrefix(strings.ToLower(s), "%s")
+	s = s[strings.IndexByte(s, '"')+1 : len(s)-1]
+	if !sensitive && strings.IndexFunc(s, unicode.IsLetter) != -1 {
+		return stringCI(s)
+	}
+	if len(s) == 1 {
+		return rune(s[0])
+	}
+	return s
+}
+
+// numVal converts a numeric value, e.g. %x41-5A, %d13.10 or %b1.
+func (i importer) numVal(n *ast.Node) (interface{}, error) {
+	base := 16
+	switch unicode.ToLower(rune(n.Value[1])) {
+	case 'b':
+		base = 2
+	case 'd':
+		base = 10
+	}
+	var runes []rune
+	s := n.Value[2:]
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '.' || r == '-'
+	}) {
+		r, err := strconv.ParseUint(v, base, 32)
+		if err != nil || unicode.MaxRune < r {
+			return nil, importError(n, i.data, "invalid numeric value %s", n.Value)
+		}
+		runes = append(runes, rune(r))
+	}
+	switch {
+	case strings.Contains(s, "-"):
+		if runes[1] < runes[0] {
+			return nil, importError(n, i.data, "invalid range %s", n.Value)
+		}
+		return runeRange{min: runes[0], max: runes[1]}, nil
+	case len(runes) == 1:
+		return runes[0], nil
+	default:
+		return string(runes), nil
+	}
+}
@@ -0,0 +1,247 @@
+package abnf_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/abnf"
+	"strings"
+	"testing"
+)
+
+func ExampleCompile() {
+	g, err := abnf.Compile([]byte(`
+; RFC 7230, section 2.6
+HTTP-version = HTTP-name "/" DIGIT "." DIGIT
+HTTP-name    = %x48.54.54.50 ; "HTTP", case-sensitive
+`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(g.Parse("HTTP-version", []byte("HTTP/1.1")))
+	fmt.Println(g.Parse("HTTP-version", []byte("http/1.1")))
+	// Output:
+	// ["HTTP-version",[["HTTP-name","HTTP"]]] <nil>
+	// <nil> parse conflict [00:000]: expected string "HTTP" but got 'h'
+}
+
+func ExampleCompile_alternatives() {
+	g, _ := abnf.Compile([]byte(`
+method = %s"GET" / %s"HEAD"
+method =/ "post"            ; case-insensitive
+version = 1*2DIGIT ["." 1*DIGIT]
+`))
+	fmt.Println(g.Parse("method", []byte("HEAD")))
+	fmt.Println(g.Parse("method", []byte("Post")))
+	fmt.Println(g.Parse("method", []byte("head")))
+	fmt.Println(g.Parse("version", []byte("10.0")))
+	// Output:
+	// ["method","HEAD"] <nil>
+	// ["method","Post"] <nil>
+	// <nil> parse conflict [00:001]: expected op.Or or["GET" "HEAD" "post"i] but got "he"
+	// ["version","10.0"] <nil>
+}
+
+func ExampleGenerate() {
+	src, _ := abnf.Generate([]byte(`
+key-value = key "=" 1*VCHAR
+key       = ALPHA *(ALPHA / DIGIT / %x2D-2E)
+`), "config")
+	fmt.Print(string(src))
+	// Output:
+	// // Code generated by abnf.Generate. DO NOT EDIT.
+	//
+	// package config
+	//
+	// import (
+	// 	"github.com/di-wu/parser"
+	// 	"github.com/di-wu/parser/abnf"
+	// 	"github.com/di-wu/parser/ast"
+	// 	"github.com/di-wu/parser/op"
+	// )
+	//
+	// func KeyValue(p *ast.Parser) (*ast.Node, error) {
+	// 	return p.Expect(
+	// 		ast.Capture{
+	// 			Type:        KeyValueType,
+	// 			TypeStrings: NodeTypes,
+	// 			Value: op.And{
+	// 				Key,
+	// 				'=',
+	// 				op.MinOne(
+	// 					abnf.VCHAR,
+	// 				),
+	// 			},
+	// 		},
+	// 	)
+	// }
+	//
+	// func Key(p *ast.Parser) (*ast.Node, error) {
+	// 	return p.Expect(
+	// 		ast.Capture{
+	// 			Type:        KeyType,
+	// 			TypeStrings: NodeTypes,
+	// 			Value: op.And{
+	// 				abnf.ALPHA,
+	// 				op.MinZero(
+	// 					op.Or{
+	// 						abnf.ALPHA,
+	// 						abnf.DIGIT,
+	// 						parser.CheckRuneRange(0x2D, 0x2E),
+	// 					},
+	// 				),
+	// 			},
+	// 		},
+	// 	)
+	// }
+	//
+	// // Node Types
+	// const (
+	// 	Unknown = iota
+	//
+	// 	KeyValueType // 001
+	// 	KeyType      // 002
+	// )
+	//
+	// var NodeTypes = []string{
+	// 	"UNKNOWN",
+	//
+	// 	"key-value",
+	// 	"key",
+	// }
+}
+
+func TestCompile_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{
+			grammar: "a = b\nb = %x30\nb = %x31\n",
+			err:     "abnf [02:000]: rule b is already defined",
+		},
+		{
+			grammar: "a = b\nB =/ %x30\n",
+			err:     "abnf [01:000]: incremental alternatives for undefined rule B",
+		},
+		{
+			grammar: "a = 1*DIGIT\n    <any text>\n",
+			err:     "abnf [01:004]: prose value <any text> can not be imported",
+		},
+		{
+			grammar: "a = %x5A-41\n",
+			err:     "abnf [00:004]: invalid range %x5A-41",
+		},
+		{
+			grammar: "a = 3*2DIGIT\n",
+			err:     "abnf [00:004]: invalid repeat 3*2",
+		},
+		{
+			grammar: "a = b / c\nc = d\n",
+			err:     "grammar: undefined rules: [b d]",
+		},
+	} {
+		if _, err := abnf.Compile([]byte(test.grammar)); err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
+
+// The grammar of ABNF itself, from section 4 of RFC 5234 and RFC 7405. The
+// alternatives of defined-as and repeat are swapped, the first alternative that
+// matches wins.
+const rfc5234 = `rulelist       =  1*( rule / (*c-wsp c-nl) )
+
+rule           =  rulename defined-as elements c-nl
+                       ; continues if next line starts
+                       ;  with white space
+
+rulename       =  ALPHA *(ALPHA / DIGIT / "-")
+
+defined-as     =  *c-wsp ("=/" / "=") *c-wsp
+                       ; basic rules definition and
+                       ;  incremental alternatives
+
+elements       =  alternation *c-wsp
+
+c-wsp          =  WSP / (c-nl WSP)
+
+c-nl           =  comment / CRLF
+                       ; comment or newline
+
+comment        =  ";" *(WSP / VCHAR) CRLF
+
+alternation    =  concatenation
+                  *(*c-wsp "/" *c-wsp concatenation)
+
+concatenation  =  repetition *(1*c-wsp repetition)
+
+repetition     =  [repeat] element
+
+repeat         =  (*DIGIT "*" *DIGIT) / 1*DIGIT
+
+element        =  rulename / group / option /
+                  char-val / num-val / prose-val
+
+group          =  "(" *c-wsp alternation *c-wsp ")"
+
+option         =  "[" *c-wsp alternation *c-wsp "]"
+
+char-val       =  case-insensitive-string /
+                  case-sensitive-string
+
+case-insensitive-string =
+                  [ "%i" ] quoted-string
+
+case-sensitive-string =
+                  "%s" quoted-string
+
+quoted-string  =  DQUOTE *(%x20-21 / %x23-7E) DQUOTE
+                       ; quoted string of SP and VCHAR
+                       ;  without DQUOTE
+
+num-val        =  "%" (bin-val / dec-val / hex-val)
+
+bin-val        =  "b" 1*BIT
+                  [ 1*("." 1*BIT) / ("-" 1*BIT) ]
+                       ; series of concatenated bit values
+                       ;  or single ONEOF range
+
+dec-val        =  "d" 1*DIGIT
+                  [ 1*("." 1*DIGIT) / ("-" 1*DIGIT) ]
+
+hex-val        =  "x" 1*HEXDIG
+                  [ 1*("." 1*HEXDIG) / ("-" 1*HEXDIG) ]
+
+prose-val      =  "<" *(%x20-3D / %x3F-7E) ">"
+                       ; bracketed string of SP and VCHAR
+                       ;  without angles
+                       ; prose description, to be used as
+                       ;  last resort
+`
+
+// TestCompile_rfc5234 checks whether the compiled grammar of ABNF is able to
+// parse itself.
+func TestCompile_rfc5234(t *testing.T) {
+	g, err := abnf.Compile([]byte(rfc5234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ABNF requires CRLF line endings.
+	data := []byte(strings.ReplaceAll(rfc5234, "\n", "\r\n"))
+	n, err := g.Parse("rulelist", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.End != len(data) {
+		t.Fatalf("parsed %d of %d bytes", n.End, len(data))
+	}
+	var rules int
+	for _, c := range n.Children() {
+		if c.TypeString() == "rule" {
+			rules++
+		}
+	}
+	if rules != 24 {
+		t.Errorf("expected 24 rules, got %d", rules)
+	}
+}
@@ -9,8 +9,17 @@ type Capture struct {
 	Type int
 	// TypeStrings contains all the string representations of the available types.
 	TypeStrings []string
-	// Value is the expression to capture the value of the node.
+	// Value is the expression to capture the value of the node. If it is an
+	// op.Quoted, then the decoded string is stored as the result of the node.
 	Value interface{}
+	// Action, if set, gets called with the matched value and the nodes
+	// produced by the expression. The returned value is stored as the result
+	// of the node. An error indicates that the value did not match.
+	//
+	// If the expression returns a single node with a type, then that node is
+	// returned instead of a new one. The result is stored on that node, which
+	// replaces the result of its own capture.
+	Action func(match string, children []*Node) (interface{}, error)
 }
 
 func (c Capture) String() string {
@@ -19,3 +28,21 @@ func (c Capture) String() string {
 	}
 	return fmt.Sprintf("{%03d}", c.Type)
 }
+
+// ActionError is an error that occurs when the action of a capture fails.
+type ActionError struct {
+	// Capture of which the action failed.
+	Capture Capture
+	// Match is the value that was passed to the action.
+	Match string
+	// Err is the error returned by the action.
+	Err error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("action %s: %q: %v", e.Capture, e.Match, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
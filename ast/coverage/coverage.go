@@ -0,0 +1,416 @@
+// Package coverage measures which parts of a grammar get exercised by the ast
+// parser. It records, per rule and per expression within that rule, how many
+// times it was attempted, matched and failed.
+package coverage
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Counts keeps track of the outcome of the attempts to match a value.
+type Counts struct {
+	// Attempts is the number of times the parser tried to match the value.
+	Attempts int
+	// Matches is the number of times the value was matched.
+	Matches int
+	// Failures is the number of times the value was not matched.
+	Failures int
+}
+
+func (c *Counts) add(err error) {
+	c.Attempts++
+	if err == nil {
+		c.Matches++
+	} else {
+		c.Failures++
+	}
+}
+
+// Rule represents the coverage of a single rule. Rules are ParseNode functions
+// and LoopUp keys. Values that are expected outside of any rule are recorded as
+// a rule of their own, named after the value.
+type Rule struct {
+	// Name of the rule.
+	Name string
+	// File and Line point to the definition of the rule, if known.
+	File string
+	Line int
+	// Aliases contains the type strings of the values captured by the rule.
+	// These are often the names used in the grammar (e.g. PEGN) of the rule.
+	Aliases []string
+
+	Counts
+	// Expressions contains the coverage of the expressions within the rule.
+	Expressions []*Expression
+
+	expressions map[string]*Expression
+}
+
+// Expression represents the coverage of an expression within a rule.
+type Expression struct {
+	// Path is the location of the expression within its rule. Every element is
+	// the index of the expression within its parent.
+	Path []int
+	// Value is a string representation of the expression.
+	Value string
+	// Alternative is the index of the expression within an op.Or or op.XOr. It
+	// is -1 if the parent of the expression is not an alternation.
+	Alternative int
+
+	Counts
+	// Range is non nil if the expression is an op.Range.
+	Range *Range
+}
+
+// Range keeps track of how many times the value of an op.Range was repeated.
+type Range struct {
+	// Min and Max are the bounds of the range.
+	Min, Max int
+	// Fewest and Most are the lowest and highest number of repetitions that
+	// were matched.
+	Fewest, Most int
+}
+
+// MinCovered returns whether a match with exactly the minimum number of
+// repetitions was recorded.
+func (r *Range) MinCovered() bool {
+	return r.Fewest == r.Min
+}
+
+// MaxCovered returns whether a match with exactly the maximum number of
+// repetitions was recorded. Unbounded ranges are covered if the value was
+// repeated more than the minimum.
+func (r *Range) MaxCovered() bool {
+	if r.Max == -1 {
+		return r.Min < r.Most
+	}
+	return r.Most == r.Max
+}
+
+// PathString returns the path of the expression as a dotted string.
+func (e *Expression) PathString() string {
+	path := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		path[i] = strconv.Itoa(p)
+	}
+	return strings.Join(path, ".")
+}
+
+// Coverage records the coverage of all the parsers it observes. It is safe to
+// observe multiple parsers concurrently.
+type Coverage struct {
+	mu    sync.Mutex
+	rules map[string]*Rule
+}
+
+// New creates a new Coverage.
+func New() *Coverage {
+	return &Coverage{
+		rules: make(map[string]*Rule),
+	}
+}
+
+// Observer creates a new observer that records into the coverage. Every parser
+// needs its own observer, so this function can be used for ast.NewObserver.
+func (c *Coverage) Observer() ast.Observer {
+	return &observer{c: c}
+}
+
+// Attach attaches a new observer to the given parser.
+func (c *Coverage) Attach(p *ast.Parser) {
+	p.SetObserver(c.Observer())
+}
+
+// Rules returns (a copy of) the coverage of all recorded rules, sorted by
+// name. The expressions of the rules are sorted by path.
+func (c *Coverage) Rules() []*Rule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var rules []*Rule
+	for _, r := range c.rules {
+		rule := *r
+		rule.Aliases = append([]string(nil), r.Aliases...)
+		rule.Expressions = nil
+		for _, e := range r.expressions {
+			expr := *e
+			if e.Range != nil {
+				rng := *e.Range
+				expr.Range = &rng
+			}
+			rule.Expressions = append(rule.Expressions, &expr)
+		}
+		rule.expressions = nil
+		sort.Slice(rule.Expressions, func(i, j int) bool {
+			return less(rule.Expressions[i].Path, rule.Expressions[j].Path)
+		})
+		rules = append(rules, &rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+	return rules
+}
+
+// less compares two paths.
+func less(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// rule returns the rule with the given name, it gets created if it does not
+// exist yet. Expects the lock to be held.
+func (c *Coverage) rule(name string, i interface{}) *Rule {
+	if r, ok := c.rules[name]; ok {
+		return r
+	}
+	r := &Rule{
+		Name:        name,
+		expressions: make(map[string]*Expression),
+	}
+	if f, ok := i.(ast.ParseNode); ok {
+		pc := reflect.ValueOf(f).Pointer()
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			r.File, r.Line = fn.FileLine(fn.Entry())
+		}
+	}
+	c.rules[name] = r
+	return r
+}
+
+// expression returns the expression of the given rule at the given path, it
+// gets created if it does not exist yet. Expects the lock to be held.
+func (r *Rule) expression(path []int, i interface{}, alternative int) *Expression {
+	key := fmtPath(path)
+	if e, ok := r.expressions[key]; ok {
+		return e
+	}
+	e := &Expression{
+		Path:        append([]int(nil), path...),
+		Value:       describe(i),
+		Alternative: alternative,
+	}
+	if v, ok := i.(op.Range); ok {
+		e.Range = &Range{
+			Min:    v.Min,
+			Max:    v.Max,
+			Fewest: -1,
+			Most:   -1,
+		}
+	}
+	r.expressions[key] = e
+	return e
+}
+
+func fmtPath(path []int) string {
+	key := make([]byte, 0, len(path)*2)
+	for _, p := range path {
+		key = strconv.AppendInt(key, int64(p), 10)
+		key = append(key, '.')
+	}
+	return string(key)
+}
+
+// describe returns a short string representation of the given value.
+func describe(i interface{}) string {
+	if name, ok := ast.RuleName(i); ok {
+		return name
+	}
+	switch v := ast.ConvertAliases(i).(type) {
+	case ast.Capture:
+		return "capture " + v.String()
+	case op.Not:
+		return "!" + describe(v.Value)
+	case op.Ensure:
+		return "&" + describe(v.Value)
+	case op.And:
+		return describeAll("and", v)
+	case op.Or:
+		return describeAll("or", v)
+	case op.XOr:
+		return describeAll("xor", v)
+	case op.Range:
+		switch {
+		case v.Min == 0 && v.Max == -1:
+			return describe(v.Value) + "*"
+		case v.Min == 1 && v.Max == -1:
+			return describe(v.Value) + "+"
+		case v.Min == 0 && v.Max == 1:
+			return describe(v.Value) + "?"
+		}
+		return describe(v.Value) + "{" + strconv.Itoa(v.Min) + ":" + strconv.Itoa(v.Max) + "}"
+	default:
+		return parser.Stringer(v)
+	}
+}
+
+func describeAll(name string, values []interface{}) string {
+	all := make([]string, len(values))
+	for i, v := range values {
+		all[i] = describe(v)
+	}
+	return name + "[" + strings.Join(all, " ") + "]"
+}
+
+// children returns the (static) children of the given value.
+func children(i interface{}) []interface{} {
+	switch v := i.(type) {
+	case op.And:
+		return v
+	case op.Or:
+		return v
+	case op.XOr:
+		return v
+	case op.Range:
+		return []interface{}{v.Value}
+	case op.Not:
+		return []interface{}{v.Value}
+	case op.Ensure:
+		return []interface{}{v.Value}
+	case ast.Capture:
+		return []interface{}{v.Value}
+	default:
+		return nil
+	}
+}
+
+// frame is a value that is currently being matched by the parser.
+type frame struct {
+	// rule is the rule the value is part of.
+	rule *Rule
+	// path is the path of the value within the rule.
+	path []int
+	// value that is being matched.
+	value interface{}
+	// expr is the expression of the value, nil if it is the start of a rule.
+	expr *Expression
+	// reference is the expression that referenced the rule, nil if the value
+	// is not the start of a rule or if it was not referenced by another rule.
+	reference *Expression
+	// entered is the number of children that were entered.
+	entered int
+	// matched is the number of children that were matched.
+	matched int
+}
+
+// observer records the coverage of a single parser.
+type observer struct {
+	c     *Coverage
+	stack []*frame
+}
+
+func (o *observer) Enter(i interface{}, _ *parser.Cursor) {
+	o.c.mu.Lock()
+	defer o.c.mu.Unlock()
+
+	f := &frame{value: i}
+	if len(o.stack) != 0 {
+		parent := o.stack[len(o.stack)-1]
+		index, alternative := parent.entered, -1
+		switch parent.value.(type) {
+		case op.Or, op.XOr:
+			alternative = index
+		case op.And, ast.ParseNode:
+			// The children of a sequence are entered in order.
+		default:
+			// All other values only have a single child, which can get entered
+			// multiple times. e.g. op.Range.
+			index = 0
+		}
+		parent.entered++
+
+		f.rule = parent.rule
+		f.path = appendPath(parent.path, index)
+		f.expr = f.rule.expression(f.path, i, alternative)
+	}
+	if name, ok := ast.RuleName(i); ok {
+		// The value is the start of a (new) rule.
+		f.reference, f.expr = f.expr, nil
+		f.rule, f.path = o.c.rule(name, i), nil
+	} else if f.rule == nil {
+		// Values outside of any rule are recorded as a rule of their own.
+		f.rule = o.c.rule(describe(i), i)
+	}
+	if c, ok := i.(ast.Capture); ok {
+		f.rule.alias(c)
+	}
+
+	// Register all the children so that the ones that never get attempted also
+	// show up in the coverage.
+	for index, child := range children(i) {
+		alternative := -1
+		switch i.(type) {
+		case op.Or, op.XOr:
+			alternative = index
+		}
+		f.rule.expression(appendPath(f.path, index), ast.ConvertAliases(child), alternative)
+	}
+
+	o.stack = append(o.stack, f)
+}
+
+// appendPath returns a copy of the path with the given index appended to it.
+func appendPath(path []int, index int) []int {
+	return append(append(make([]int, 0, len(path)+1), path...), index)
+}
+
+func (o *observer) Exit(_ interface{}, _ *parser.Cursor, _ *ast.Node, err error) {
+	o.c.mu.Lock()
+	defer o.c.mu.Unlock()
+
+	if len(o.stack) == 0 {
+		return
+	}
+	f := o.stack[len(o.stack)-1]
+	o.stack = o.stack[:len(o.stack)-1]
+
+	if f.expr != nil {
+		f.expr.add(err)
+		if f.expr.Range != nil && err == nil {
+			f.expr.Range.observe(f.matched)
+		}
+	} else {
+		f.rule.add(err)
+	}
+	if f.reference != nil {
+		f.reference.add(err)
+	}
+	if len(o.stack) != 0 && err == nil {
+		o.stack[len(o.stack)-1].matched++
+	}
+}
+
+// alias adds the type string of the given capture to the aliases of the rule.
+func (r *Rule) alias(c ast.Capture) {
+	if c.Type < 0 || len(c.TypeStrings) <= c.Type {
+		return
+	}
+	alias := c.TypeStrings[c.Type]
+	for _, a := range r.Aliases {
+		if a == alias {
+			return
+		}
+	}
+	r.Aliases = append(r.Aliases, alias)
+}
+
+func (r *Range) observe(n int) {
+	if r.Fewest == -1 || n < r.Fewest {
+		r.Fewest = n
+	}
+	if r.Most < n {
+		r.Most = n
+	}
+}
@@ -0,0 +1,158 @@
+package coverage_test
+
+import (
+	"bytes"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/ast/coverage"
+	"github.com/di-wu/parser/op"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Value(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		TypeStrings: []string{"Value"},
+		Value: op.Or{
+			'0',
+			op.And{
+				parser.CheckRuneRange('1', '9'),
+				op.MinZero(parser.CheckRuneRange('0', '9')),
+			},
+			'-',
+		},
+	})
+}
+
+func Example() {
+	c := coverage.New()
+	for _, input := range []string{"0", "7", "42"} {
+		p, _ := ast.New([]byte(input))
+		c.Attach(p)
+		_, _ = p.Expect(Value)
+	}
+	_ = c.WriteText(os.Stdout)
+	// Output:
+	// RULE         EXPRESSION                         ATTEMPTS  MATCHES  FAILURES
+	// Value                                           3         3        0
+	//   0          capture Value                      3         3        0
+	//   0.0        or['0' and['1'-'9' '0'-'9'*] '-']  3         3        0
+	//   0.0.0      '0'                                3         1        2
+	//   0.0.1      and['1'-'9' '0'-'9'*]              2         2        0
+	//   0.0.1.0    '1'-'9'                            2         2        0
+	//   0.0.1.1    '0'-'9'*                           2         2        0
+	//   0.0.1.1.0  '0'-'9'                            3         1        2
+	//   0.0.2      '-'                                0         0        0         never attempted
+	// 2 of 3 alternatives matched
+}
+
+func TestCoverage_NewObserver(t *testing.T) {
+	c := coverage.New()
+	ast.NewObserver = c.Observer
+	defer func() { ast.NewObserver = nil }()
+
+	if _, err := ast.Parse([]byte("1"), Value); err != nil {
+		t.Fatal(err)
+	}
+	rules := c.Rules()
+	if len(rules) != 1 {
+		t.Fatal(rules)
+	}
+	if r := rules[0]; r.Name != "Value" || r.Matches != 1 {
+		t.Error(r.Name, r.Counts)
+	}
+	if r := rules[0]; len(r.Aliases) != 1 || r.Aliases[0] != "Value" {
+		t.Error(r.Aliases)
+	}
+}
+
+func TestCoverage_range(t *testing.T) {
+	c := coverage.New()
+	p, _ := ast.New([]byte("aa"))
+	c.Attach(p)
+	if _, err := p.Expect(func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(op.MinMax(1, 3, 'a'))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	e := c.Rules()[0].Expressions
+	if len(e) != 2 {
+		t.Fatal(e)
+	}
+	if r := e[0]; r.Range == nil || r.Range.Fewest != 2 || r.Range.Most != 2 {
+		t.Error(r.Range)
+	} else if r.Range.MinCovered() || r.Range.MaxCovered() {
+		t.Error(r.Range.MinCovered(), r.Range.MaxCovered())
+	}
+	if r := e[1]; r.Attempts != 3 || r.Matches != 2 {
+		t.Error(r.Counts)
+	}
+}
+
+func TestCoverage_WriteHTML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	grammar := filepath.Join(dir, "grammar.pegn")
+	if err := ioutil.WriteFile(grammar, []byte("Value <-- '0' / [1-9] [0-9]* / '-'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := coverage.New()
+	p, _ := ast.New([]byte("0"))
+	c.Attach(p)
+	_, _ = p.Expect(Value)
+
+	var buf bytes.Buffer
+	if err := c.WriteHTML(&buf, grammar); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, s := range []string{
+		"coverage_test.go",
+		"grammar.pegn",
+		`class="line partial" title="Value: 1 attempts, 1 matches, 0 failures"`,
+		"0.0.2 &#39;-&#39;: never attempted",
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %q", s)
+		}
+	}
+}
+
+func TestCoverage_skipper(t *testing.T) {
+	c := coverage.New()
+	p, _ := ast.New([]byte("a c"))
+	p.SetSkipper(' ')
+	c.Attach(p)
+	if _, err := p.Expect(func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(op.And{"a", op.Or{"b", "c"}})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The skipper is not part of the rule.
+	e := c.Rules()[0].Expressions
+	if len(e) != 5 {
+		t.Fatal(len(e))
+	}
+	for i, expected := range []struct {
+		value    string
+		attempts int
+		matches  int
+	}{
+		{"and[\"a\" or[\"b\" \"c\"]]", 1, 1},
+		{"\"a\"", 1, 1},
+		{"or[\"b\" \"c\"]", 1, 1},
+		{"\"b\"", 1, 0},
+		{"\"c\"", 1, 1},
+	} {
+		if r := e[i]; r.Value != expected.value || r.Attempts != expected.attempts || r.Matches != expected.matches {
+			t.Errorf("%v %s: %v", r.Path, r.Value, r.Counts)
+		}
+	}
+}
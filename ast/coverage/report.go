@@ -0,0 +1,240 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText writes a plain text report to the given writer. Every rule is
+// followed by the expressions it consists of.
+func (c *Coverage) WriteText(w io.Writer) error {
+	var (
+		rules = c.Rules()
+		buf   bytes.Buffer
+		tw    = tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+		alternatives, matched int
+	)
+	fmt.Fprintln(tw, "RULE\tEXPRESSION\tATTEMPTS\tMATCHES\tFAILURES\t")
+	for _, r := range rules {
+		fmt.Fprintf(tw, "%s\t\t%d\t%d\t%d\t%s\n",
+			r.Name, r.Attempts, r.Matches, r.Failures, note(r.Counts),
+		)
+		for _, e := range r.Expressions {
+			fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t%d\t%s\n",
+				e.PathString(), shorten(e.Value, 48),
+				e.Attempts, e.Matches, e.Failures, e.note(),
+			)
+			if e.Alternative != -1 {
+				alternatives++
+				if e.Matches != 0 {
+					matched++
+				}
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		// Remove the padding of the empty trailing columns.
+		if _, err := io.WriteString(w, strings.TrimRight(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d alternatives matched\n", matched, alternatives)
+	return err
+}
+
+// note returns a short remark about the given counts, if any.
+func note(c Counts) string {
+	switch {
+	case c.Attempts == 0:
+		return "never attempted"
+	case c.Matches == 0:
+		return "never matched"
+	default:
+		return ""
+	}
+}
+
+func (e *Expression) note() string {
+	if n := note(e.Counts); n != "" || e.Range == nil {
+		return n
+	}
+	var missed []string
+	if !e.Range.MinCovered() {
+		missed = append(missed, "min")
+	}
+	if !e.Range.MaxCovered() {
+		missed = append(missed, "max")
+	}
+	if len(missed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"repeated %d to %d times, %s not reached",
+		e.Range.Fewest, e.Range.Most, strings.Join(missed, " and "),
+	)
+}
+
+// shorten truncates the given string to the given amount of runes.
+func shorten(s string, n int) string {
+	if r := []rune(s); n < len(r) {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
+
+// definition matches the definition of a rule in a PEGN grammar.
+var definition = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9_]*)\s*<--?`)
+
+// WriteHTML writes an HTML report to the given writer. It annotates the source
+// files in which the (ParseNode) rules are defined and the given grammar files
+// (e.g. .pegn files) with the coverage of the rules they define. Rules are
+// matched with the definitions in grammar files by their name or by the type
+// strings of the values they capture.
+func (c *Coverage) WriteHTML(w io.Writer, grammars ...string) error {
+	var (
+		rules  = c.Rules()
+		byName = make(map[string]*Rule)
+		lines  = make(map[string]map[int]*Rule)
+		files  []string
+	)
+	for _, r := range rules {
+		byName[r.Name] = r
+		for _, alias := range r.Aliases {
+			if _, ok := byName[alias]; !ok {
+				byName[alias] = r
+			}
+		}
+		if r.File == "" {
+			continue
+		}
+		if _, ok := lines[r.File]; !ok {
+			lines[r.File] = make(map[int]*Rule)
+			files = append(files, r.File)
+		}
+		lines[r.File][r.Line] = r
+	}
+	sort.Strings(files)
+
+	var report htmlReport
+	for _, file := range files {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		report.Files = append(report.Files, annotate(file, string(source), func(n int, _ string) *Rule {
+			return lines[file][n]
+		}))
+	}
+	for _, file := range grammars {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		report.Files = append(report.Files, annotate(file, string(source), func(_ int, line string) *Rule {
+			if m := definition.FindStringSubmatch(line); m != nil {
+				return byName[m[1]]
+			}
+			return nil
+		}))
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+// annotate annotates the lines of the given source with the rules that are
+// defined on them.
+func annotate(name, source string, rule func(n int, line string) *Rule) htmlFile {
+	file := htmlFile{Name: name}
+	for i, line := range strings.Split(source, "\n") {
+		l := htmlLine{Number: i + 1, Text: line}
+		if r := rule(i+1, line); r != nil {
+			l.Class, l.Summary = status(r)
+			for _, e := range r.Expressions {
+				if n := e.note(); n != "" {
+					l.Notes = append(l.Notes, fmt.Sprintf("%s %s: %s", e.PathString(), e.Value, n))
+				}
+			}
+		}
+		file.Lines = append(file.Lines, l)
+	}
+	return file
+}
+
+// status returns the css class and a summary of the coverage of the rule.
+func status(r *Rule) (string, string) {
+	summary := fmt.Sprintf(
+		"%s: %d attempts, %d matches, %d failures",
+		r.Name, r.Attempts, r.Matches, r.Failures,
+	)
+	if r.Matches == 0 {
+		return "uncovered", summary
+	}
+	for _, e := range r.Expressions {
+		if e.note() != "" {
+			return "partial", summary
+		}
+	}
+	return "covered", summary
+}
+
+type htmlReport struct {
+	Files []htmlFile
+}
+
+type htmlFile struct {
+	Name  string
+	Lines []htmlLine
+}
+
+type htmlLine struct {
+	Number  int
+	Text    string
+	Class   string
+	Summary string
+	Notes   []string
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Grammar Coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { font-family: monospace; margin: 0; }
+.line { white-space: pre; }
+.number { color: #999; display: inline-block; text-align: right; width: 4em; }
+.covered { background: #cfc; }
+.partial { background: #ffc; }
+.uncovered { background: #fcc; }
+.summary, .note { color: #555; font-style: italic; margin-left: 5em; }
+</style>
+</head>
+<body>
+{{- range .Files}}
+<h2>{{.Name}}</h2>
+<pre>
+{{- range .Lines}}
+<span class="line {{.Class}}"{{if .Summary}} title="{{.Summary}}"{{end}}><span class="number">{{.Number}}</span> {{.Text}}</span>
+{{- if .Summary}}
+<span class="summary">{{.Summary}}</span>
+{{- end}}
+{{- range .Notes}}
+<span class="note">{{.}}</span>
+{{- end}}
+{{- end}}
+</pre>
+{{- end}}
+</body>
+</html>
+`))
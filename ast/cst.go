@@ -0,0 +1,125 @@
+package ast
+
+import "github.com/di-wu/parser"
+
+// TokenKind is the kind of a token node. Token nodes are only produced by
+// parsers in lossless mode, see Parser.SetLossless.
+type TokenKind int
+
+const (
+	// NotToken indicates that the node is not a token, but a captured node.
+	NotToken TokenKind = iota
+	// LiteralToken is a token that is matched by a rune or string.
+	LiteralToken
+	// ClassToken is a token that is matched by a class or back reference.
+	ClassToken
+	// SkippedToken is a token that is matched by the skipper or consists of
+	// indentation.
+	SkippedToken
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case LiteralToken:
+		return "Literal"
+	case ClassToken:
+		return "Class"
+	case SkippedToken:
+		return "Skipped"
+	default:
+		return "NotToken"
+	}
+}
+
+// SetLossless enables or disables lossless mode. In lossless mode the parser
+// produces a concrete syntax tree: all the matched values that are not
+// captured (e.g. keywords, punctuation and whitespace) become token nodes
+// under the nearest capture. Concatenating the values of all the value nodes
+// reproduces the input. Captures that only match tokens still produce value
+// nodes.
+//
+// Token nodes are ignored by actions, Node.Abstract returns the tree without
+// them.
+func (ap *Parser) SetLossless(lossless bool) {
+	ap.lossless = lossless
+}
+
+// token creates a token node of the value matched since the start, if any.
+func (ap *Parser) token(kind TokenKind, start *parser.Cursor) *Node {
+	p := ap.internal
+	if !ap.lossless || p.Mark().Offset() <= start.Offset() {
+		return nil
+	}
+	return &Node{
+		Token: kind,
+		Value: p.Slice(start, p.LookBack()),
+		Start: start.Offset(),
+		End:   p.Mark().Offset(),
+	}
+}
+
+// IsToken returns whether the node is a token, see Parser.SetLossless.
+func (n *Node) IsToken() bool {
+	return n.Token != NotToken
+}
+
+// Abstract returns a copy of the node without token nodes, as if it was parsed
+// without lossless mode.
+func (n *Node) Abstract() *Node {
+	c := &Node{
+		Type:        n.Type,
+		TypeStrings: n.TypeStrings,
+		Value:       n.Value,
+		Result:      n.Result,
+		Start:       n.Start,
+		End:         n.End,
+		Trivia:      n.Trivia,
+		Token:       n.Token,
+	}
+	for _, child := range n.Children() {
+		if !child.IsToken() {
+			c.SetLast(child.Abstract())
+		}
+	}
+	return c
+}
+
+// Text returns the text of all the value nodes within the node. In lossless
+// mode this is equal to the parsed input of the node.
+func (n *Node) Text() string {
+	if !n.IsParent() {
+		return n.Value
+	}
+	var text string
+	for _, c := range n.Children() {
+		text += c.Text()
+	}
+	return text
+}
+
+// onlyTokens returns whether the node consists of only tokens.
+func onlyTokens(n *Node) bool {
+	if n.IsToken() {
+		return true
+	}
+	if n.Type != -1 {
+		return false
+	}
+	for _, c := range n.Children() {
+		if !c.IsToken() {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutTokens returns the nodes that are not tokens.
+func withoutTokens(nodes []*Node) []*Node {
+	var filtered []*Node
+	for _, n := range nodes {
+		if !n.IsToken() {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,63 @@
+package ast_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleParser_SetLossless() {
+	types := []string{"UNKNOWN", "Assignment", "Name", "Integer", "Comment"}
+	var (
+		comment = ast.Capture{
+			Type:        4,
+			TypeStrings: types,
+			Value: op.And{"//", op.MinZero(parser.CheckRuneFunc(func(r rune) bool {
+				return r != '\n' && r != parser.EOD
+			}))},
+		}
+		name = ast.Capture{
+			Type:        2,
+			TypeStrings: types,
+			Value:       op.MinOne(parser.CheckRuneRange('a', 'z')),
+		}
+		integer = ast.Capture{
+			Type:        3,
+			TypeStrings: types,
+			Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+		}
+		assignment = ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value:       op.And{"let", op.Token{Value: name}, '=', op.Token{Value: integer}, ';'},
+		}
+	)
+
+	input := "// The answer.\nlet x = 42;\n"
+	p, _ := ast.New([]byte(input))
+	p.SetSkipper(op.Or{' ', '\n', comment})
+	p.SetLossless(true)
+	node, _ := p.Expect(op.And{assignment, parser.EOD})
+	fmt.Println(node)
+	fmt.Println(node.Text() == input)
+	fmt.Println(node.Abstract())
+	// Output:
+	// ["UNKNOWN",[["Comment","// The answer."],["Skipped","\n"],["Assignment",[["Literal","let"],["Skipped"," "],["Name","x"],["Skipped"," "],["Literal","="],["Skipped"," "],["Integer","42"],["Literal",";"]]],["Skipped","\n"]]]
+	// true
+	// ["UNKNOWN",[["Comment","// The answer."],["Assignment",[["Name","x"],["Integer","42"]]]]]
+}
+
+func ExampleParser_SetLossless_operators() {
+	input := " -2 ** (1 + 2)!"
+	p, _ := ast.New([]byte(input))
+	p.SetLossless(true)
+	node, _ := p.Expect(expression.Parse)
+	fmt.Println(node)
+	fmt.Println(node.Text() == input)
+	fmt.Println(node.Abstract())
+	// Output:
+	// ["UNKNOWN",[["Literal"," "],["Neg",[["Literal","-"],["Pow",[["Integer","2"],["Literal"," "],["Literal","**"],["Fact",[["Literal"," "],["Literal","("],["Add",[["Integer","1"],["Literal"," "],["Literal","+"],["Literal"," "],["Integer","2"]]],["Literal",")"],["Literal","!"]]]]]]]]]
+	// true
+	// ["UNKNOWN",[["Neg",[["Pow",[["Integer","2"],["Fact",[["Add",[["Integer","1"],["Integer","2"]]]]]]]]]]]
+}
@@ -13,6 +13,18 @@ type Node struct {
 	TypeStrings []string
 	// Value of the node. Only possible if it has no children.
 	Value string
+	// Result is the value returned by the action of the capture that produced
+	// the node, if any. Captures of an op.Quoted default to the decoded string.
+	Result interface{}
+	// Start and End are the offsets (in bytes) of the captured value within
+	// the parsed data, the end is exclusive.
+	Start, End int
+	// Trivia are the nodes that got skipped before the node, e.g. comments.
+	// See Parser.SetSkipper.
+	Trivia []*Node
+	// Token is the kind of token, if the node is a token of a concrete syntax
+	// tree. See Parser.SetLossless.
+	Token TokenKind
 
 	// Parent is the parent node.
 	Parent *Node
@@ -27,8 +39,11 @@ type Node struct {
 }
 
 // TypeString returns the strings representation of the type. Same as TypeStrings[Type]. Returns "UNKNOWN" if not
-// string representation is found or len(TypeStrings) == 0.
+// string representation is found or len(TypeStrings) == 0. Tokens return their kind.
 func (n *Node) TypeString() string {
+	if n.IsToken() {
+		return n.Token.String()
+	}
 	if 0 <= n.Type && n.Type < len(n.TypeStrings) {
 		return n.TypeStrings[n.Type]
 	}
@@ -0,0 +1,52 @@
+package ast
+
+import (
+	"github.com/di-wu/parser"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Observer gets notified about every value the parser tries to match. This
+// allows you to trace, profile or measure the coverage of a grammar without
+// changing it.
+type Observer interface {
+	// Enter gets called before the parser tries to match the given value. The
+	// cursor points to the rune the parser is currently at.
+	Enter(i interface{}, at *parser.Cursor)
+	// Exit gets called after the parser tried to match the given value. The
+	// cursor points to the rune the parser is at after matching the value. The
+	// error is nil if the value was matched.
+	Exit(i interface{}, at *parser.Cursor, node *Node, err error)
+}
+
+// NewObserver, if set, gets used to create an Observer for every parser that
+// gets created with New or NewFromParser. This allows tooling to observe
+// parsers it does not create itself. e.g. the parsers of a test suite.
+var NewObserver func() Observer
+
+// RuleName returns the name of the rule the given value represents. Only
+// ParseNode values (the name of the function) and LoopUp values (the key) are
+// considered to be rules.
+func RuleName(i interface{}) (string, bool) {
+	switch v := ConvertAliases(i).(type) {
+	case ParseNode:
+		f := runtime.FuncForPC(reflect.ValueOf(v).Pointer())
+		if f == nil {
+			return "", false
+		}
+		// e.g. github.com/di-wu/parser/examples/calculator/ast.AddSub
+		name := f.Name()
+		if i := strings.LastIndex(name, "/"); i != -1 {
+			name = name[i+1:]
+		}
+		if i := strings.Index(name, "."); i != -1 {
+			name = name[i+1:]
+		}
+		return name, true
+	case LoopUp:
+		return v.Key, true
+	default:
+		return "", false
+	}
+}
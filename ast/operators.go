@@ -0,0 +1,259 @@
+package ast
+
+import "github.com/di-wu/parser"
+
+// Associativity defines how operators with the same precedence are grouped.
+type Associativity int
+
+const (
+	// LeftAssociative operators are grouped from the left: a - b - c is
+	// parsed as (a - b) - c.
+	LeftAssociative Associativity = iota
+	// RightAssociative operators are grouped from the right: a ^ b ^ c is
+	// parsed as a ^ (b ^ c).
+	RightAssociative
+)
+
+// Operator is an operator within an operator table.
+type Operator struct {
+	// Type of the node that gets created for the operator.
+	Type int
+	// Value is the expression that matches the operator. e.g. '+' or "**".
+	Value interface{}
+	// Precedence of the operator, operators with a higher precedence bind
+	// tighter.
+	Precedence int
+	// Associativity of the operator, only used by infix operators.
+	Associativity Associativity
+	// Separator turns an infix operator into a ternary operator. It separates
+	// the second and third operand. e.g. ':' for the operator '?' in
+	// a ? b : c. The second operand can be any expression.
+	Separator interface{}
+}
+
+// Operators is a table of operators that parses expressions by precedence
+// climbing. This removes the need of a rule per precedence level.
+//
+// The operators produce nodes with the operands as children: unary operators
+// have one child, infix operators have a left and right child and ternary
+// operators three children. The operators themselves are identified by the
+// type of the node.
+type Operators struct {
+	// TypeStrings contains all the string representations of the available
+	// types.
+	TypeStrings []string
+	// Operand is the expression of the operands, e.g. integers or identifiers.
+	Operand interface{}
+	// Prefix operators, e.g. -a.
+	Prefix []Operator
+	// Infix (and ternary) operators, e.g. a + b.
+	Infix []Operator
+	// Postfix operators, e.g. a!.
+	Postfix []Operator
+	// Open and Close, if set, are the values that group expressions, e.g. '('
+	// and ')'. Groups do not produce a node of their own.
+	Open, Close interface{}
+	// Space, if set, gets skipped in between operands and operators. e.g.
+	// op.MinZero(' ').
+	Space interface{}
+}
+
+// Parse parses an expression based on the operator table. Operators are tried
+// in the given order, so longer operators should be placed before the
+// operators they start with. e.g. "**" before '*'.
+func (o Operators) Parse(p *Parser) (*Node, error) {
+	return o.expression(p, 0)
+}
+
+// expression parses an expression that only contains operators with a
+// precedence of at least the given minimum.
+func (o Operators) expression(p *Parser, min int) (*Node, error) {
+	begin := p.internal.Mark()
+	space := o.space(p)
+	start := p.internal.Mark()
+	left, err := o.unary(p)
+	if err != nil {
+		p.internal.Jump(begin)
+		return nil, err
+	}
+	left = group(space, left)
+
+loop:
+	for {
+		mark := p.internal.Mark()
+		for _, operator := range o.Postfix {
+			if operator.Precedence < min {
+				continue
+			}
+			token, ok := o.operator(p, operator.Value)
+			if !ok {
+				continue
+			}
+			left = o.node(p, operator.Type, start, left, token)
+			continue loop
+		}
+		for _, operator := range o.Infix {
+			if operator.Precedence < min {
+				continue
+			}
+			token, ok := o.operator(p, operator.Value)
+			if !ok {
+				continue
+			}
+			next := operator.Precedence + 1
+			if operator.Associativity == RightAssociative {
+				next = operator.Precedence
+			}
+			if operator.Separator == nil {
+				right, err := o.expression(p, next)
+				if err != nil {
+					if parser.Committed(err) {
+						return nil, err
+					}
+					// Not followed by an operand, so not part of the
+					// expression.
+					p.internal.Jump(mark)
+					break loop
+				}
+				left = o.node(p, operator.Type, start, left, token, right)
+				continue loop
+			}
+
+			middle, err := o.expression(p, 0)
+			if parser.Committed(err) {
+				return nil, err
+			}
+			if err != nil {
+				p.internal.Jump(mark)
+				break loop
+			}
+			separator, ok := o.operator(p, operator.Separator)
+			if !ok {
+				p.internal.Jump(mark)
+				break loop
+			}
+			right, err := o.expression(p, next)
+			if err != nil {
+				if parser.Committed(err) {
+					return nil, err
+				}
+				p.internal.Jump(mark)
+				break loop
+			}
+			left = o.node(p, operator.Type, start, left, token, middle, separator, right)
+			continue loop
+		}
+		p.internal.Jump(mark)
+		return left, nil
+	}
+	return left, nil
+}
+
+// unary parses an operand, a group or a prefix operator followed by its
+// operand.
+func (o Operators) unary(p *Parser) (*Node, error) {
+	start := p.internal.Mark()
+	for _, operator := range o.Prefix {
+		token, ok := o.operator(p, operator.Value)
+		if !ok {
+			continue
+		}
+		operand, err := o.expression(p, operator.Precedence)
+		if err != nil {
+			p.internal.Jump(start)
+			if parser.Committed(err) {
+				return nil, err
+			}
+			continue
+		}
+		return o.node(p, operator.Type, start, token, operand), nil
+	}
+	if o.Open != nil {
+		if open, err := p.Expect(o.Open); err == nil {
+			node, err := o.expression(p, 0)
+			if err != nil {
+				p.internal.Jump(start)
+				return nil, err
+			}
+			space := o.space(p)
+			close, err := p.Expect(o.Close)
+			if err != nil {
+				p.internal.Jump(start)
+				return nil, err
+			}
+			return group(open, node, space, close), nil
+		}
+	}
+	return p.Expect(o.Operand)
+}
+
+// operator checks whether the (optional) space is followed by the given
+// operator. Resets the parser if that is not the case. The returned node
+// contains the tokens of the space and operator, in lossless mode.
+func (o Operators) operator(p *Parser, i interface{}) (*Node, bool) {
+	start := p.internal.Mark()
+	space := o.space(p)
+	token, err := p.Expect(i)
+	if err != nil {
+		p.internal.Jump(start)
+		return nil, false
+	}
+	return group(space, token), true
+}
+
+func (o Operators) space(p *Parser) *Node {
+	if o.Space == nil {
+		return nil
+	}
+	node, _ := p.Expect(o.Space)
+	return node
+}
+
+// node creates a node of the given type, that starts at the given cursor and
+// ends at the current position of the parser.
+func (o Operators) node(p *Parser, typ int, start *parser.Cursor, children ...*Node) *Node {
+	node := &Node{
+		Type:        typ,
+		TypeStrings: o.TypeStrings,
+		Start:       start.Offset(),
+		End:         p.internal.Mark().Offset(),
+	}
+	if c := group(children...); c != nil {
+		if c.Type == -1 {
+			node.Adopt(c)
+		} else {
+			node.SetLast(c)
+		}
+	}
+	if node.FirstChild != nil && node.FirstChild.Start < node.Start {
+		// Leading space, in lossless mode.
+		node.Start = node.FirstChild.Start
+	}
+	return node
+}
+
+// group groups the given nodes in a node with type -1, nil nodes are ignored.
+// Returns the node itself if there is only one.
+func group(nodes ...*Node) *Node {
+	var filtered []*Node
+	for _, n := range nodes {
+		if n != nil {
+			filtered = append(filtered, n)
+		}
+	}
+	switch len(filtered) {
+	case 0:
+		return nil
+	case 1:
+		return filtered[0]
+	}
+	g := &Node{Type: -1}
+	for _, n := range filtered {
+		if n.Type == -1 {
+			g.Adopt(n)
+		} else {
+			g.SetLast(n)
+		}
+	}
+	return g
+}
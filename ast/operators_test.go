@@ -0,0 +1,132 @@
+package ast_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+const (
+	Integer = iota + 1
+	Conditional
+	Add
+	Sub
+	Mul
+	Pow
+	Neg
+	Fact
+)
+
+var expression = ast.Operators{
+	TypeStrings: []string{"UNKNOWN", "Integer", "Conditional", "Add", "Sub", "Mul", "Pow", "Neg", "Fact"},
+	Operand: ast.Capture{
+		Type:        Integer,
+		TypeStrings: []string{"UNKNOWN", "Integer"},
+		Value: op.MinOne(parser.CheckRuneFunc(func(r rune) bool {
+			return '0' <= r && r <= '9'
+		})),
+	},
+	Prefix: []ast.Operator{
+		{Type: Neg, Value: '-', Precedence: 4},
+	},
+	Infix: []ast.Operator{
+		{Type: Conditional, Value: '?', Separator: ':', Precedence: 1, Associativity: ast.RightAssociative},
+		{Type: Add, Value: '+', Precedence: 2},
+		{Type: Sub, Value: '-', Precedence: 2},
+		{Type: Pow, Value: "**", Precedence: 5, Associativity: ast.RightAssociative},
+		{Type: Mul, Value: '*', Precedence: 3},
+	},
+	Postfix: []ast.Operator{
+		{Type: Fact, Value: '!', Precedence: 6},
+	},
+	Open:  '(',
+	Close: ')',
+	Space: op.MinZero(' '),
+}
+
+func ExampleOperators() {
+	for _, s := range []string{
+		"1 + 2 * 3",
+		"1 - 2 - 3",
+		"2 ** 3 ** 2",
+		"-2 ** 2",
+		"(1 + 2) * 3!",
+		"1 ? 2 : 0 ? 3 : 4",
+	} {
+		fmt.Println(ast.Parse([]byte(s), expression.Parse))
+	}
+	// Output:
+	// ["Add",[["Integer","1"],["Mul",[["Integer","2"],["Integer","3"]]]]] <nil>
+	// ["Sub",[["Sub",[["Integer","1"],["Integer","2"]]],["Integer","3"]]] <nil>
+	// ["Pow",[["Integer","2"],["Pow",[["Integer","3"],["Integer","2"]]]]] <nil>
+	// ["Neg",[["Pow",[["Integer","2"],["Integer","2"]]]]] <nil>
+	// ["Mul",[["Add",[["Integer","1"],["Integer","2"]]],["Fact",[["Integer","3"]]]]] <nil>
+	// ["Conditional",[["Integer","1"],["Integer","2"],["Conditional",[["Integer","0"],["Integer","3"],["Integer","4"]]]]] <nil>
+}
+
+func ExampleOperators_evaluate() {
+	var evaluate func(n *ast.Node) int
+	evaluate = func(n *ast.Node) int {
+		if n.Type == Integer {
+			var i int
+			_, _ = fmt.Sscan(n.Value, &i)
+			return i
+		}
+		operands := n.Children()
+		switch n.Type {
+		case Conditional:
+			if evaluate(operands[0]) != 0 {
+				return evaluate(operands[1])
+			}
+			return evaluate(operands[2])
+		case Add:
+			return evaluate(operands[0]) + evaluate(operands[1])
+		case Sub:
+			return evaluate(operands[0]) - evaluate(operands[1])
+		case Mul:
+			return evaluate(operands[0]) * evaluate(operands[1])
+		case Pow:
+			x, y := evaluate(operands[0]), evaluate(operands[1])
+			v := 1
+			for ; 0 < y; y-- {
+				v *= x
+			}
+			return v
+		case Neg:
+			return -evaluate(operands[0])
+		case Fact:
+			v := 1
+			for i := evaluate(operands[0]); 1 < i; i-- {
+				v *= i
+			}
+			return v
+		}
+		return 0
+	}
+
+	for _, s := range []string{
+		"1 + 2 * 3",
+		"1 - 2 - 3",
+		"2 ** 3 ** 2",
+		"(1 + 2) * 3!",
+		"0 ? 1 : -(2 - 5)",
+	} {
+		n, _ := ast.Parse([]byte(s), expression.Parse)
+		fmt.Println(evaluate(n))
+	}
+	// Output:
+	// 7
+	// -4
+	// 512
+	// 18
+	// 3
+}
+
+func ExampleOperators_incomplete() {
+	p, _ := ast.New([]byte("1 + 2 +"))
+	n, _ := p.Expect(expression.Parse)
+	fmt.Println(n, n.Start, n.End)
+	// Output:
+	// ["Add",[["Integer","1"],["Integer","2"]]] 0 5
+}
@@ -3,6 +3,7 @@ package ast
 import (
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/op"
+	"unicode"
 )
 
 // Parse parses the given data based on the parse node.
@@ -11,7 +12,7 @@ func Parse(data []byte, node ParseNode) (*Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	return node(p)
+	return p.Expect(node)
 }
 
 // Parser represents a general purpose AST parser.
@@ -20,6 +21,17 @@ type Parser struct {
 
 	converter func(interface{}) interface{}
 	operator  func(interface{}) (*Node, error)
+	observer  Observer
+
+	// skipper gets skipped before tokens, if not within an op.Token. lexical
+	// keeps track of the depth of the latter.
+	skipper interface{}
+	lexical int
+	// depth is the number of nested Expect calls, a cut does not affect the
+	// values outside of the outermost call.
+	depth int
+	// lossless indicates whether tokens are kept, see SetLossless.
+	lossless bool
 }
 
 // New creates a new Parser.
@@ -43,21 +55,110 @@ func (ap *Parser) SetOperator(o func(i interface{}) (*Node, error)) {
 	ap.operator = o
 }
 
+// SetObserver allows you to observe all the values the parser tries to match.
+func (ap *Parser) SetObserver(o Observer) {
+	ap.observer = o
+}
+
+// SetValue stores the value under the given key, see parser.Parser.SetValue.
+// The value gets discarded if the parser backtracks to before it was set.
+func (ap *Parser) SetValue(key, value interface{}) {
+	ap.internal.SetValue(key, value)
+}
+
+// Value returns the value that is stored under the given key, nil if there is
+// none.
+func (ap *Parser) Value(key interface{}) interface{} {
+	return ap.internal.Value(key)
+}
+
+// SetRecovery registers a value to recover from the failure of an op.Expect
+// with the given label, see parser.Parser.SetRecovery.
+func (ap *Parser) SetRecovery(label string, i interface{}) {
+	ap.internal.SetRecovery(label, i)
+}
+
+// Recovered returns the errors the parser recovered from, in the order they
+// occurred.
+func (ap *Parser) Recovered() []*parser.ExpectedParseError {
+	return ap.internal.Recovered()
+}
+
 // NewFromParser creates a new Parser from a parser.Parser. This allows you to
 // customize the internal parser. If no customization is needed, use New.
 func NewFromParser(p *parser.Parser) (*Parser, error) {
-	return &Parser{
+	ap := Parser{
 		internal: p,
-	}, nil
+	}
+	if NewObserver != nil {
+		ap.observer = NewObserver()
+	}
+	return &ap, nil
 }
 
 // Expect checks whether the buffer contains the given value.
 func (ap *Parser) Expect(i interface{}) (*Node, error) {
+	ap.depth++
+	node, err := ap.match(i)
+	if ap.depth--; ap.depth == 0 {
+		if e, ok := err.(*parser.CutError); ok {
+			// A cut without an enclosing op.Or.
+			return node, e.Err
+		}
+	}
+	return node, err
+}
+
+// match checks whether the buffer contains the given value, the skipper gets
+// skipped before tokens.
+func (ap *Parser) match(i interface{}) (*Node, error) {
 	i = ConvertAliases(i)
 	if ap.converter != nil {
 		i = ap.converter(i)
 	}
 
+	if ap.skipper != nil && ap.lexical == 0 && isToken(i) {
+		start := ap.internal.Mark()
+		skipped := ap.skip()
+		node, err := ap.observe(i)
+		if err != nil {
+			ap.internal.Jump(start)
+			return nil, err
+		}
+		if len(skipped) != 0 {
+			// Lossless mode, keep the skipped nodes.
+			group := &Node{Type: -1}
+			for _, n := range append(skipped, node) {
+				if n == nil {
+					continue
+				}
+				if n.Type == -1 {
+					group.Adopt(n)
+				} else {
+					group.SetLast(n)
+				}
+			}
+			return group, nil
+		}
+		return node, nil
+	}
+	return ap.observe(i)
+}
+
+// observe checks whether the buffer contains the given (converted) value and
+// notifies the observer, if any.
+func (ap *Parser) observe(i interface{}) (*Node, error) {
+	if ap.observer == nil {
+		return ap.expect(i)
+	}
+	ap.observer.Enter(i, ap.internal.Mark())
+	node, err := ap.expect(i)
+	ap.observer.Exit(i, ap.internal.Mark(), node, err)
+	return node, err
+}
+
+// expect checks whether the buffer contains the given (converted) value.
+func (ap *Parser) expect(i interface{}) (*Node, error) {
 	p := ap.internal
 	start := p.Mark()
 	if ap.operator != nil {
@@ -74,11 +175,24 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		}
 	}
 	switch v := i.(type) {
-	case rune, string, parser.AnonymousClass:
+	case rune, string, parser.AnonymousClass, parser.CharSet, parser.UnicodeClass, *unicode.RangeTable, op.Quoted, op.Indent, op.Dedent, op.SameIndent, op.BackRef, op.Cut, parser.Class:
+		if q, ok := v.(op.Quoted); ok && q.Key == nil {
+			// Keeps the decoded string, see Capture.
+			q.Key = quotedKey{}
+			v = q
+		}
 		// Just check if it matches.
 		if _, err := p.Expect(v); err != nil {
 			return nil, err
 		}
+		switch v.(type) {
+		case rune, string, op.Quoted:
+			return ap.token(LiteralToken, start), nil
+		case op.Indent, op.SameIndent:
+			return ap.token(SkippedToken, start), nil
+		default:
+			return ap.token(ClassToken, start), nil
+		}
 
 	case ParseNode:
 		node, err := v(ap)
@@ -89,27 +203,58 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		return node, nil
 
 	case Capture:
+		var trivia []*Node
+		if ap.skipper != nil {
+			trivia = ap.takeTrivia()
+		}
 		node, err := ap.Expect(v.Value)
 		if err != nil {
 			p.Jump(start)
 			return nil, err
 		}
-		if node != nil {
+		if node != nil && !onlyTokens(node) {
+			if len(trivia) != 0 {
+				node.Trivia = append(trivia, node.Trivia...)
+			}
 			// Return the node.
+			children := []*Node{node}
 			if node.Type == -1 {
+				children = withoutTokens(node.Children())
 				node.Type = v.Type
+				node.Start, node.End = start.Offset(), p.Mark().Offset()
 			}
 			if len(node.TypeStrings) == 0 {
 				node.TypeStrings = v.TypeStrings
 			}
+			if v.Action != nil {
+				if err := ap.action(v, node, start, children); err != nil {
+					return nil, err
+				}
+			}
 			return node, nil
 		}
 
-		return &Node{
+		node = &Node{
 			Type:        v.Type,
 			TypeStrings: v.TypeStrings,
 			Value:       p.Slice(start, p.LookBack()),
-		}, nil
+			Start:       start.Offset(),
+			End:         p.Mark().Offset(),
+			Trivia:      trivia,
+		}
+		if q, ok := v.Value.(op.Quoted); ok {
+			key := q.Key
+			if key == nil {
+				key = quotedKey{}
+			}
+			node.Result = p.Value(key)
+		}
+		if v.Action != nil {
+			if err := ap.action(v, node, start, nil); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
 
 	case LoopUp:
 		i, err := v.Get()
@@ -118,23 +263,86 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		}
 		return ap.Expect(i)
 
+	case op.Token:
+		ap.lexical++
+		defer func() { ap.lexical-- }()
+		return ap.Expect(v.Value)
+	case op.Expect:
+		node, err := ap.Expect(v.Value)
+		if err == nil {
+			return node, nil
+		}
+		if e, ok := err.(*parser.ExpectedParseError); ok && e.Label != "" {
+			// Labeled by a nested op.Expect.
+			return nil, err
+		}
+		labeled := p.LabeledError(v, start, err)
+		if r, ok := p.Recovery(v.Label); ok {
+			if node, err := ap.Expect(r); err == nil {
+				p.AddRecovered(labeled)
+				return node, nil
+			}
+		}
+		return nil, labeled
+	case op.Named:
+		var skipped []*Node
+		if ap.skipper != nil && ap.lexical == 0 {
+			// The capture starts after the skipped values.
+			skipped = ap.skip()
+		}
+		from := p.Mark()
+		node, err := ap.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		var captured string
+		if from.Offset() < p.Mark().Offset() {
+			captured = p.Slice(from, p.LookBack())
+		} else if node == nil {
+			// Nothing matched, the skipped values belong to the next token.
+			p.Jump(start)
+			skipped = nil
+		}
+		p.SetValue(op.BackRef{Name: v.Name}, captured)
+		// Lossless mode, keep the skipped nodes.
+		return group(append(skipped, node)...), nil
 	case op.Not:
 		defer p.Jump(start)
-		if _, err := ap.Expect(v.Value); err == nil {
+		_, err := ap.Expect(v.Value)
+		if err == nil {
 			// Return error if match is found.
 			return nil, p.ExpectedParseError(v, start, p.LookBack())
 		}
+		// A labeled failure is an error in the data (e.g. a missing closing
+		// parenthesis) rather than a mismatch, the lookahead does not turn it
+		// into a match.
+		if e, ok := err.(*parser.ExpectedParseError); ok && e.Label != "" {
+			return nil, err
+		}
 	case op.Ensure:
 		if n, err := ap.Expect(v.Value); err != nil {
+			if err, ok := err.(*parser.CutError); ok {
+				return n, err.Err
+			}
 			return n, err
 		}
 		p.Jump(start)
 	case op.And:
-		node := &Node{Type: -1}
+		var (
+			node = &Node{Type: -1}
+			cut  bool
+		)
 		for _, i := range v {
+			if _, ok := i.(op.Cut); ok {
+				cut = true
+			}
 			n, err := ap.Expect(i)
 			if err != nil {
 				p.Jump(start)
+				if !parser.Committed(err) && cut {
+					return nil, &parser.CutError{Err: err}
+				}
 				return nil, err
 			}
 			if n != nil {
@@ -164,6 +372,14 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 				break
 			}
 			p.Jump(start)
+			if err, ok := err.(*parser.CutError); ok {
+				// Committed to this alternative, the cut does not affect the
+				// enclosing values.
+				return nil, err.Err
+			}
+			if parser.Committed(err) {
+				return nil, err
+			}
 		}
 		if !hit {
 			return nil, p.ExpectedParseError(v, start, p.Peek())
@@ -177,6 +393,12 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 			n, err := ap.Expect(i)
 			if err != nil {
 				p.Jump(start)
+				if err, ok := err.(*parser.CutError); ok {
+					return nil, err.Err
+				}
+				if parser.Committed(err) {
+					return nil, err
+				}
 				continue
 			}
 			if last != nil {
@@ -202,6 +424,10 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		for {
 			n, err := ap.Expect(v.Value)
 			if err != nil {
+				if parser.Committed(err) {
+					p.Jump(start)
+					return nil, err
+				}
 				break
 			}
 			if n != nil {
@@ -241,6 +467,30 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 	return nil, nil
 }
 
+// action calls the action of the capture and stores the result on the node.
+// Resets the parser to the start if the action fails.
+func (ap *Parser) action(c Capture, node *Node, start *parser.Cursor, children []*Node) error {
+	var match string
+	if start.Offset() < ap.internal.Mark().Offset() {
+		match = ap.internal.Slice(start, ap.internal.LookBack())
+	}
+	result, err := c.Action(match, children)
+	if err != nil {
+		ap.internal.Jump(start)
+		return &ActionError{
+			Capture: c,
+			Match:   match,
+			Err:     err,
+		}
+	}
+	node.Result = result
+	return nil
+}
+
+// quotedKey is the key under which the decoded string of an op.Quoted without
+// a key is stored.
+type quotedKey struct{}
+
 // ConvertAliases converts various default primitive types to aliases for type
 // matching.
 func ConvertAliases(i interface{}) interface{} {
@@ -5,6 +5,7 @@ import (
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/ast"
 	"github.com/di-wu/parser/op"
+	"strconv"
 	"testing"
 )
 
@@ -100,6 +101,57 @@ func ExampleParser_Expect_capture() {
 	// ["Digit","2"] <nil>
 }
 
+func ExampleParser_Expect_action() {
+	number := ast.Capture{
+		Type:        1,
+		TypeStrings: []string{"UNKNOWN", "Number", "Sum"},
+		Value: op.MinOne(parser.CheckRuneFunc(func(r rune) bool {
+			return '0' <= r && r <= '9'
+		})),
+		Action: func(match string, _ []*ast.Node) (interface{}, error) {
+			return strconv.ParseUint(match, 10, 16)
+		},
+	}
+	sum := ast.Capture{
+		Type:        2,
+		TypeStrings: number.TypeStrings,
+		Value:       op.And{number, op.MinZero(op.And{'+', number})},
+		Action: func(_ string, children []*ast.Node) (interface{}, error) {
+			var sum uint64
+			for _, c := range children {
+				sum += c.Result.(uint64)
+			}
+			return sum, nil
+		},
+	}
+
+	p, _ := ast.New([]byte("1+20+300"))
+	n, err := p.Expect(sum)
+	fmt.Println(n, n.Result, err)
+
+	// A capture of a single node returns that node, the result of the action
+	// replaces its result.
+	double := ast.Capture{
+		Type:        2,
+		TypeStrings: number.TypeStrings,
+		Value:       number,
+		Action: func(_ string, children []*ast.Node) (interface{}, error) {
+			return 2 * children[0].Result.(uint64), nil
+		},
+	}
+	p, _ = ast.New([]byte("5"))
+	n, err = p.Expect(double)
+	fmt.Println(n, n.Result, err)
+
+	// A failing action is the same as a value that does not match.
+	p, _ = ast.New([]byte("70000"))
+	fmt.Println(p.Expect(sum))
+	// Output:
+	// ["Sum",[["Number","1"],["Number","20"],["Number","300"]]] 321 <nil>
+	// ["Number","5"] 10 <nil>
+	// <nil> action Number: "70000": strconv.ParseUint: parsing "70000": value out of range
+}
+
 func ExampleParser_Expect_not() {
 	p, _ := ast.New([]byte("bar"))
 
@@ -273,3 +325,185 @@ func ExampleParser_Expect_range() {
 	// ["3A","aaa"] <nil>
 	// <nil> parse conflict [00:003]: expected op.Range 'a'{4:-1} but got "aaa"
 }
+
+func ExampleParser_Expect_indentation() {
+	types := []string{"UNKNOWN", "Item", "Name"}
+	var item, items func(p *ast.Parser) (*ast.Node, error)
+	item = func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value: op.And{
+				ast.Capture{
+					Type:        2,
+					TypeStrings: types,
+					Value:       op.MinOne(parser.CheckRuneRange('a', 'z')),
+				},
+				op.Optional('\n'),
+				op.Optional(op.And{op.Indent{}, items, op.Dedent{}}),
+			},
+		})
+	}
+	items = func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(op.And{item, op.MinZero(op.And{op.SameIndent{}, item})})
+	}
+
+	p, _ := ast.New([]byte("a\n  b\n  c\n    d\ne"))
+	fmt.Println(p.Expect(op.And{items, parser.EOD}))
+	// Output:
+	// ["UNKNOWN",[["Item",[["Name","a"],["Item",[["Name","b"]]],["Item",[["Name","c"],["Item",[["Name","d"]]]]]]],["Item",[["Name","e"]]]]] <nil>
+}
+
+func ExampleParser_Expect_back_reference() {
+	types := []string{"UNKNOWN", "Fence", "Code"}
+	// Markdown fenced code blocks, the closing fence has the same length.
+	fence := op.And{
+		op.Named{Name: "fence", Value: ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value:       op.Min(3, '`'),
+		}},
+		'\n',
+		ast.Capture{
+			Type:        2,
+			TypeStrings: types,
+			Value: op.MinZero(op.And{
+				op.Not{Value: op.And{'\n', op.BackRef{Name: "fence"}}},
+				parser.CheckRuneFunc(func(r rune) bool { return r != parser.EOD }),
+			}),
+		},
+		'\n', op.BackRef{Name: "fence"},
+	}
+
+	p, _ := ast.New([]byte("````\n```\n````"))
+	fmt.Println(p.Expect(fence))
+	p, _ = ast.New([]byte("```\ncode\n``\n```"))
+	fmt.Println(p.Expect(fence))
+
+	// The capture starts after the skipped values.
+	p, _ = ast.New([]byte("  ``` ```"))
+	p.SetSkipper(' ')
+	fmt.Println(p.Expect(op.And{
+		op.Named{Name: "fence", Value: ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value:       op.Token{Value: op.Min(3, '`')},
+		}},
+		op.BackRef{Name: "fence"},
+	}))
+	// Output:
+	// ["UNKNOWN",[["Fence","````"],["Code","```"]]] <nil>
+	// ["UNKNOWN",[["Fence","```"],["Code","code\n``"]]] <nil>
+	// ["UNKNOWN",[["Fence","```"]]] <nil>
+}
+
+func ExampleParser_Expect_quoted() {
+	str := ast.Capture{
+		TypeStrings: []string{"String"},
+		Value:       op.JSONString,
+	}
+
+	p, _ := ast.New([]byte(`"a\tb"`))
+	node, err := p.Expect(str)
+	fmt.Printf("%s %q %v\n", node, node.Result, err)
+	p, _ = ast.New([]byte(`"a\z"`))
+	fmt.Println(p.Expect(str))
+	// Output:
+	// ["String","\"a\\tb\""] "a\tb" <nil>
+	// <nil> parse conflict [00:003]: invalid escape sequence: expected parser.CharSet ["/bfnrtux5C] but got 'z'
+}
+
+func ExampleParser_Expect_cut() {
+	types := []string{"UNKNOWN", "If", "Call"}
+	statement := op.Or{
+		ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value:       op.And{"if", op.Cut{}, " (", op.MinOne(parser.CheckRuneRange('a', 'z')), ")"},
+		},
+		ast.Capture{
+			Type:        2,
+			TypeStrings: types,
+			Value:       op.And{op.MinOne(parser.CheckRuneRange('a', 'z')), "()"},
+		},
+	}
+
+	// Without the cut the last statement would result in the error of the
+	// op.Or, instead of the missing parenthesis.
+	for _, s := range []string{"if (x)", "print()", "if (x"} {
+		p, _ := ast.New([]byte(s))
+		fmt.Println(p.Expect(statement))
+	}
+	// Output:
+	// ["If","if (x)"] <nil>
+	// ["Call","print()"] <nil>
+	// <nil> parse conflict [00:005]: expected string ")" but got ""
+}
+
+func TestParser_Expect_cut(t *testing.T) {
+	statement := op.And{"if", op.Cut{}, '('}
+
+	p, _ := ast.New([]byte("ify"))
+	if _, err := p.Expect(op.Or{op.Or{statement, "iff"}, "ify"}); err != nil {
+		t.Error(err)
+	}
+	p, _ = ast.New([]byte("ifx"))
+	if _, err := p.Expect(op.Not{Value: statement}); err != nil {
+		t.Error(err)
+	}
+	p, _ = ast.New([]byte("ifx"))
+	if _, err := p.Expect(op.Ensure{Value: statement}); err == nil {
+		t.Error("expected an error")
+	} else if _, ok := err.(*parser.CutError); ok {
+		t.Error(err)
+	}
+	// Without an enclosing op.Or.
+	if _, err := ast.Parse([]byte("ifx"), func(p *ast.Parser) (*ast.Node, error) {
+		return p.Expect(statement)
+	}); err == nil {
+		t.Error("expected an error")
+	} else if _, ok := err.(*parser.ExpectedParseError); !ok {
+		t.Errorf("%T", err)
+	}
+}
+
+func TestParser_Expect_notLabeled(t *testing.T) {
+	group := op.And{'(', 'x', op.Expect{Value: ')', Label: "missing closing parenthesis"}}
+
+	p, _ := ast.New([]byte("(y)"))
+	if _, err := p.Expect(op.Not{Value: group}); err != nil {
+		t.Error(err)
+	}
+	// Labeled failures are not a mismatch of the value.
+	p, _ = ast.New([]byte("(x]"))
+	_, err := p.Expect(op.Not{Value: group})
+	if e, ok := err.(*parser.ExpectedParseError); !ok || e.Label != "missing closing parenthesis" {
+		t.Error(err)
+	}
+}
+
+func ExampleParser_SetRecovery() {
+	types := []string{"UNKNOWN", "Statement"}
+	statement := ast.Capture{
+		Type:        1,
+		TypeStrings: types,
+		Value: op.And{
+			op.MinOne(parser.CheckRuneRange('a', 'z')),
+			op.Expect{Value: ';', Label: "missing semicolon"},
+		},
+	}
+
+	p, _ := ast.New([]byte("a;b c;d;e f g;"))
+	// Skip to the next semicolon.
+	p.SetRecovery("missing semicolon", op.And{op.MinZero(parser.CheckRuneFunc(func(r rune) bool {
+		return r != ';' && r != parser.EOD
+	})), ';'})
+	fmt.Println(p.Expect(op.MinZero(statement)))
+	for _, err := range p.Recovered() {
+		fmt.Println(err)
+	}
+	// Output:
+	// ["UNKNOWN",[["Statement","a;"],["Statement","b c;"],["Statement","d;"],["Statement","e f g;"]]] <nil>
+	// parse conflict [00:003]: missing semicolon: expected int32 ';' but got ' '
+	// parse conflict [00:009]: missing semicolon: expected int32 ';' but got ' '
+}
@@ -0,0 +1,200 @@
+package profile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteText writes a plain text report to the given writer. The rules are
+// sorted by their cumulative time.
+func (p *Profiler) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "CALLS\tFAILURES\tSELF\tCUMULATIVE\tRESCANNED\t RULE")
+	for _, r := range p.Rules() {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%dB\t %s\n",
+			r.Calls, r.Failures, r.Self, r.Cumulative, r.Rescanned, r.Name,
+		)
+	}
+	return tw.Flush()
+}
+
+// WritePprof writes the samples of the profiler in the (gzip compressed)
+// protocol buffer format of pprof. This allows you to inspect the profile with
+// `go tool pprof`. Every rule is represented as a function.
+//
+// The profile contains the following sample types:
+//   - calls/count
+//   - failures/count
+//   - time/nanoseconds (self)
+//   - rescanned/bytes
+func (p *Profiler) WritePprof(w io.Writer) error {
+	p.mu.Lock()
+	var (
+		b       protobuf
+		table   = newStringTable()
+		samples = make([]*sample, 0, len(p.samples))
+	)
+	for _, s := range p.samples {
+		samples = append(samples, s)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return stackKey(samples[i].stack) < stackKey(samples[j].stack)
+	})
+
+	for _, t := range [][2]string{
+		{"calls", "count"},
+		{"failures", "count"},
+		{"time", "nanoseconds"},
+		{"rescanned", "bytes"},
+	} {
+		var vt protobuf
+		vt.int64(1, table.index(t[0]))
+		vt.int64(2, table.index(t[1]))
+		b.message(1, vt) // sample_type
+	}
+	for _, s := range samples {
+		var sm protobuf
+		locations := make([]uint64, len(s.stack))
+		for i, r := range s.stack {
+			locations[i] = r.id
+		}
+		sm.packedUint64(1, locations)
+		sm.packedInt64(2, []int64{s.calls, s.failures, int64(s.self), s.rescanned})
+		b.message(2, sm) // sample
+	}
+	for _, r := range p.order {
+		var line protobuf
+		line.uint64(1, r.id)
+		line.int64(2, int64(r.Line))
+		var loc protobuf
+		loc.uint64(1, r.id)
+		loc.message(4, line)
+		b.message(4, loc) // location
+	}
+	for _, r := range p.order {
+		var fn protobuf
+		fn.uint64(1, r.id)
+		fn.int64(2, table.index(r.Name))
+		fn.int64(3, table.index(r.Name))
+		fn.int64(4, table.index(r.File))
+		fn.int64(5, int64(r.Line))
+		b.message(5, fn) // function
+	}
+	start := p.start
+	p.mu.Unlock()
+
+	var pt protobuf
+	pt.int64(1, table.index("time"))
+	pt.int64(2, table.index("nanoseconds"))
+	defaultType := table.index("time")
+	for _, s := range table.table {
+		b.string(6, s) // string_table
+	}
+	b.int64(9, start.UnixNano())          // time_nanos
+	b.int64(10, int64(time.Since(start))) // duration_nanos
+	b.message(11, pt)                     // period_type
+	b.int64(12, 1)                        // period
+	b.int64(14, defaultType)              // default_sample_type
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func stackKey(stack []*Rule) string {
+	names := make([]string, len(stack))
+	for i, r := range stack {
+		names[i] = r.Name
+	}
+	return strings.Join(names, "\x00")
+}
+
+// stringTable is the string table of a profile. The first entry is always the
+// empty string.
+type stringTable struct {
+	table   []string
+	indices map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		table:   []string{""},
+		indices: map[string]int64{"": 0},
+	}
+}
+
+// index returns the index of the given string, it gets added to the table if
+// it is not present yet.
+func (t *stringTable) index(s string) int64 {
+	if i, ok := t.indices[s]; ok {
+		return i
+	}
+	i := int64(len(t.table))
+	t.table = append(t.table, s)
+	t.indices[s] = i
+	return i
+}
+
+// protobuf is a minimal protocol buffer encoder.
+type protobuf []byte
+
+func (b *protobuf) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *protobuf) key(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *protobuf) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(v)
+}
+
+func (b *protobuf) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *protobuf) bytes(field int, v []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protobuf) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *protobuf) message(field int, m protobuf) {
+	b.bytes(field, m)
+}
+
+func (b *protobuf) packedUint64(field int, vs []uint64) {
+	var p protobuf
+	for _, v := range vs {
+		p.varint(v)
+	}
+	b.bytes(field, p)
+}
+
+func (b *protobuf) packedInt64(field int, vs []int64) {
+	var p protobuf
+	for _, v := range vs {
+		p.varint(uint64(v))
+	}
+	b.bytes(field, p)
+}
@@ -0,0 +1,284 @@
+// Package profile measures where the ast parser spends its time. It aggregates,
+// per rule, the time spent in the rule, the number of invocations and failed
+// attempts, and the amount of bytes that got scanned more than once because of
+// backtracking.
+package profile
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule contains the measurements of a single rule. Rules are ParseNode
+// functions, LoopUp keys and captures with a type string. A capture that is the
+// value of a rule with the same name is part of the invocation of that rule.
+type Rule struct {
+	// Name of the rule.
+	Name string
+	// File and Line point to the definition of the rule, if known.
+	File string
+	Line int
+
+	// Calls is the number of times the rule was invoked.
+	Calls int
+	// Failures is the number of invocations that did not match.
+	Failures int
+	// Self is the time spent in the rule itself, excluding the time spent in
+	// the rules it invoked.
+	Self time.Duration
+	// Cumulative is the time spent in the rule, including the time spent in
+	// the rules it invoked. Recursive invocations are only counted once.
+	Cumulative time.Duration
+	// Rescanned is the amount of bytes the rule scanned that were already
+	// scanned before, i.e. the cost of backtracking. It is an approximation
+	// based on the positions at which the values were entered and exited.
+	Rescanned int
+
+	// id is the (one based) identifier of the rule within the profiler.
+	id uint64
+}
+
+// Profiler records the measurements of all the parsers it observes. It is safe
+// to observe multiple parsers concurrently.
+type Profiler struct {
+	mu      sync.Mutex
+	start   time.Time
+	rules   map[string]*Rule
+	order   []*Rule
+	samples map[string]*sample
+}
+
+// sample is the aggregated measurement of a unique stack of rules.
+type sample struct {
+	// stack of rules, the leaf is the first element.
+	stack     []*Rule
+	calls     int64
+	failures  int64
+	self      time.Duration
+	rescanned int64
+}
+
+// New creates a new Profiler.
+func New() *Profiler {
+	return &Profiler{
+		start:   time.Now(),
+		rules:   make(map[string]*Rule),
+		samples: make(map[string]*sample),
+	}
+}
+
+// Observer creates a new observer that records into the profiler. Every parser
+// needs its own observer, so this function can be used for ast.NewObserver.
+func (p *Profiler) Observer() ast.Observer {
+	return &observer{p: p}
+}
+
+// Attach attaches a new observer to the given parser.
+func (p *Profiler) Attach(ap *ast.Parser) {
+	ap.SetObserver(p.Observer())
+}
+
+// Rules returns (a copy of) the measurements of all the rules, sorted by their
+// cumulative time. The rule that took the longest comes first.
+func (p *Profiler) Rules() []Rule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rules := make([]Rule, len(p.order))
+	for i, r := range p.order {
+		rules[i] = *r
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Cumulative > rules[j].Cumulative
+	})
+	return rules
+}
+
+// rule returns the rule with the given name, it gets created if it does not
+// exist yet. Expects the lock to be held.
+func (p *Profiler) rule(name string, i interface{}) *Rule {
+	if r, ok := p.rules[name]; ok {
+		return r
+	}
+	r := &Rule{
+		Name: name,
+		id:   uint64(len(p.order) + 1),
+	}
+	if f, ok := i.(ast.ParseNode); ok {
+		pc := reflect.ValueOf(f).Pointer()
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			r.File, r.Line = fn.FileLine(fn.Entry())
+		}
+	}
+	p.rules[name] = r
+	p.order = append(p.order, r)
+	return r
+}
+
+// record adds a sample for the given stack. Expects the lock to be held.
+func (p *Profiler) record(stack []*frame, self time.Duration, failed bool, rescanned int) {
+	var (
+		rules = make([]*Rule, len(stack))
+		key   strings.Builder
+	)
+	for i := range stack {
+		// The leaf comes first.
+		r := stack[len(stack)-1-i].rule
+		rules[i] = r
+		key.WriteString(r.Name)
+		key.WriteByte(0)
+	}
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &sample{stack: rules}
+		p.samples[key.String()] = s
+	}
+	s.calls++
+	if failed {
+		s.failures++
+	}
+	s.self += self
+	s.rescanned += int64(rescanned)
+}
+
+// unit returns the name of the rule the value represents, if any.
+func unit(i interface{}) (string, bool) {
+	if name, ok := ast.RuleName(i); ok {
+		return name, true
+	}
+	if c, ok := i.(ast.Capture); ok {
+		if 0 <= c.Type && c.Type < len(c.TypeStrings) {
+			return c.TypeStrings[c.Type], true
+		}
+	}
+	return "", false
+}
+
+// frame is an invocation of a rule.
+type frame struct {
+	rule  *Rule
+	start time.Time
+	// offset at which the rule was entered.
+	offset int
+	// furthest is the furthest offset that was scanned before the rule was
+	// entered.
+	furthest int
+	// reached is the furthest offset that was reached within the rule.
+	reached int
+	// children is the time spent in the rules invoked by this rule.
+	children time.Duration
+}
+
+// observer records the measurements of a single parser.
+type observer struct {
+	p *Profiler
+	// values contains the frames of all the entered values, nil if the value
+	// is not a rule.
+	values []*frame
+	// frames contains the frames of all the entered rules.
+	frames []*frame
+	// active is the number of active invocations per rule.
+	active map[*Rule]int
+	// furthest is the furthest offset that was scanned by the parser.
+	furthest int
+}
+
+// reach updates the furthest offsets with the given offset.
+func (o *observer) reach(offset int) {
+	if o.furthest < offset {
+		o.furthest = offset
+	}
+	if len(o.frames) != 0 {
+		if f := o.frames[len(o.frames)-1]; f.reached < offset {
+			f.reached = offset
+		}
+	}
+}
+
+func (o *observer) Enter(i interface{}, at *parser.Cursor) {
+	o.reach(at.Offset())
+	name, ok := unit(i)
+	if !ok {
+		o.values = append(o.values, nil)
+		return
+	}
+
+	o.p.mu.Lock()
+	r := o.p.rule(name, i)
+	o.p.mu.Unlock()
+	if n := len(o.values); n != 0 && o.values[n-1] != nil && o.values[n-1].rule == r {
+		// The capture of a rule (ParseNode) has the same name, it is part of
+		// the same invocation.
+		o.values = append(o.values, nil)
+		return
+	}
+
+	f := &frame{
+		rule:     r,
+		offset:   at.Offset(),
+		furthest: o.furthest,
+		reached:  at.Offset(),
+	}
+	if o.active == nil {
+		o.active = make(map[*Rule]int)
+	}
+	o.active[r]++
+	o.values = append(o.values, f)
+	o.frames = append(o.frames, f)
+	f.start = time.Now()
+}
+
+func (o *observer) Exit(_ interface{}, at *parser.Cursor, _ *ast.Node, err error) {
+	end := time.Now()
+	o.reach(at.Offset())
+	if len(o.values) == 0 {
+		return
+	}
+	f := o.values[len(o.values)-1]
+	o.values = o.values[:len(o.values)-1]
+	if f == nil {
+		return
+	}
+
+	var (
+		total     = end.Sub(f.start)
+		self      = total - f.children
+		rescanned = f.reached
+	)
+	if f.furthest < rescanned {
+		rescanned = f.furthest
+	}
+	if rescanned -= f.offset; rescanned < 0 {
+		rescanned = 0
+	}
+
+	o.p.mu.Lock()
+	o.p.record(o.frames, self, err != nil, rescanned)
+	r := f.rule
+	r.Calls++
+	if err != nil {
+		r.Failures++
+	}
+	r.Self += self
+	if o.active[r]--; o.active[r] == 0 {
+		// Only the outermost invocation of recursive rules is counted.
+		r.Cumulative += total
+	}
+	r.Rescanned += rescanned
+	o.p.mu.Unlock()
+
+	o.frames = o.frames[:len(o.frames)-1]
+	if len(o.frames) != 0 {
+		parent := o.frames[len(o.frames)-1]
+		parent.children += total
+		if parent.reached < f.reached {
+			parent.reached = f.reached
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package profile_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/ast/profile"
+	"github.com/di-wu/parser/op"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func List(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		TypeStrings: []string{"List"},
+		Value: op.And{
+			Item,
+			op.MinZero(op.And{',', Item}),
+		},
+	})
+}
+
+// Item tries a key-value pair first, which needs to backtrack if the item is a
+// plain number.
+func Item(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.Or{
+		op.And{Number, '=', Number},
+		Number,
+	})
+}
+
+func Number(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		TypeStrings: []string{"Number"},
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+	})
+}
+
+func TestProfiler(t *testing.T) {
+	prof := profile.New()
+	p, _ := ast.New([]byte("1=2,34,5"))
+	prof.Attach(p)
+	if _, err := p.Expect(List); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := make(map[string]profile.Rule)
+	for _, r := range prof.Rules() {
+		rules[r.Name] = r
+	}
+	// The ParseNode functions and their captures share the same name.
+	if len(rules) != 3 {
+		t.Fatal(rules)
+	}
+	if r := rules["List"]; r.Calls != 1 || r.Failures != 0 {
+		t.Error(r)
+	}
+	if r := rules["Item"]; r.Calls != 3 || r.Failures != 0 {
+		t.Error(r)
+	}
+	if r := rules["Number"]; r.Calls != 6 || r.Failures != 0 {
+		// 1, 2, 34, 34, 5, 5
+		t.Error(r)
+	}
+	if r := rules["Number"]; r.Rescanned != 3 {
+		// "34" + "5", both are scanned twice.
+		t.Error(r)
+	}
+	if r := rules["List"]; r.Cumulative < r.Self || r.Cumulative < rules["Item"].Cumulative {
+		t.Error(r)
+	}
+	if r := rules["Number"]; r.File == "" || r.Line == 0 {
+		t.Error(r)
+	}
+
+	var text bytes.Buffer
+	if err := prof.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(text.String()), "\n"); len(lines) != 4 {
+		t.Error(text.String())
+	}
+}
+
+func TestProfiler_WritePprof(t *testing.T) {
+	prof := profile.New()
+	p, _ := ast.New([]byte("1=2,3"))
+	prof.Attach(p)
+	if _, err := p.Expect(List); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := prof.WritePprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[uint64]int)
+	var strs []string
+	for len(raw) != 0 {
+		key, n := varint(raw)
+		raw = raw[n:]
+		field, wire := key>>3, key&7
+		fields[field]++
+		switch wire {
+		case 0:
+			_, n = varint(raw)
+			raw = raw[n:]
+		case 2:
+			l, n := varint(raw)
+			if field == 6 {
+				strs = append(strs, string(raw[n:n+int(l)]))
+			}
+			raw = raw[n+int(l):]
+		default:
+			t.Fatal(wire)
+		}
+	}
+	if fields[1] != 4 || fields[4] != 3 || fields[5] != 3 || fields[2] == 0 {
+		t.Error(fields)
+	}
+	if strs[0] != "" {
+		t.Error(strs)
+	}
+	for _, name := range []string{"List", "Item", "Number", "calls", "rescanned", "profile_test.go"} {
+		var found bool
+		for _, s := range strs {
+			found = found || strings.HasSuffix(s, name)
+		}
+		if !found {
+			t.Error(name, strs)
+		}
+	}
+}
+
+func varint(b []byte) (uint64, int) {
+	var v uint64
+	for i, c := range b {
+		v |= uint64(c&0x7f) << (7 * uint(i))
+		if c < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, len(b)
+}
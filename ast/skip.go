@@ -0,0 +1,76 @@
+package ast
+
+import "github.com/di-wu/parser/op"
+
+// SetSkipper sets a value that gets skipped (zero or more times) before every
+// token: runes, strings, op.Quoted, back references, op.Token values and
+// captures. e.g. whitespace and comments. Nothing gets skipped within op.Token
+// values.
+//
+// The nodes produced by the skipper (e.g. captured comments) are kept as the
+// trivia of the next capture, see Node.Trivia.
+func (ap *Parser) SetSkipper(i interface{}) {
+	ap.skipper = i
+}
+
+// Trivia returns the skipped nodes that are not attached to a node yet. e.g.
+// the comments at the end of the data.
+func (ap *Parser) Trivia() []*Node {
+	trivia, _ := ap.internal.Value(triviaKey{}).([]*Node)
+	return trivia
+}
+
+// triviaKey is the key of the pending trivia within the values of the parser.
+// This way the trivia get discarded when the parser backtracks.
+type triviaKey struct{}
+
+// skip skips the values of the skipper and stores the nodes it produced as
+// pending trivia. In lossless mode the nodes are returned instead. The observer
+// does not get notified, the skipper is not part of the grammar.
+func (ap *Parser) skip() []*Node {
+	observer := ap.observer
+	ap.observer = nil
+	ap.lexical++
+	node, _ := ap.Expect(op.MinZero(ap.skipper))
+	ap.lexical--
+	ap.observer = observer
+	if node == nil {
+		return nil
+	}
+	skipped := []*Node{node}
+	if node.Type == -1 {
+		skipped = node.Children()
+		for _, n := range skipped {
+			n.Remove()
+		}
+	}
+	if ap.lossless {
+		for _, n := range skipped {
+			if n.IsToken() {
+				n.Token = SkippedToken
+			}
+		}
+		return skipped
+	}
+	ap.internal.SetValue(triviaKey{}, append(append([]*Node(nil), ap.Trivia()...), skipped...))
+	return nil
+}
+
+// takeTrivia returns the pending trivia and clears them.
+func (ap *Parser) takeTrivia() []*Node {
+	trivia := ap.Trivia()
+	if len(trivia) != 0 {
+		ap.internal.SetValue(triviaKey{}, []*Node(nil))
+	}
+	return trivia
+}
+
+// isToken checks whether the skipper gets applied before the given value.
+func isToken(i interface{}) bool {
+	switch i.(type) {
+	case rune, string, op.Quoted, op.BackRef, op.Token, Capture:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,50 @@
+package ast_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleParser_SetSkipper() {
+	types := []string{"UNKNOWN", "Assignment", "Name", "Integer", "Comment"}
+	var (
+		comment = ast.Capture{
+			Type:        4,
+			TypeStrings: types,
+			Value: op.And{"//", op.MinZero(parser.CheckRuneFunc(func(r rune) bool {
+				return r != '\n' && r != parser.EOD
+			}))},
+		}
+		name = ast.Capture{
+			Type:        2,
+			TypeStrings: types,
+			Value:       op.MinOne(parser.CheckRuneRange('a', 'z')),
+		}
+		integer = ast.Capture{
+			Type:        3,
+			TypeStrings: types,
+			Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+		}
+		assignment = ast.Capture{
+			Type:        1,
+			TypeStrings: types,
+			Value:       op.And{op.Token{Value: name}, '=', op.Token{Value: integer}, ';'},
+		}
+	)
+
+	p, _ := ast.New([]byte("// The answer.\nx = 42;\n\ny=1 ; // One.\n"))
+	p.SetSkipper(op.Or{' ', '\n', comment})
+	node, err := p.Expect(op.And{op.MinZero(assignment), parser.EOD})
+	fmt.Println(node, err)
+	for _, n := range node.Children() {
+		fmt.Println(n.Start, n.End, n.Trivia)
+	}
+	fmt.Println(p.Trivia())
+	// Output:
+	// ["UNKNOWN",[["Assignment",[["Name","x"],["Integer","42"]]],["Assignment",[["Name","y"],["Integer","1"]]]]] <nil>
+	// 15 22 [["Comment","// The answer."]]
+	// 24 29 []
+	// [["Comment","// One."]]
+}
@@ -0,0 +1,263 @@
+package ast
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Unmarshal stores the values of the node (and its children) in the value
+// pointed to by v. The children are mapped to the fields of structs by their
+// type strings, which are defined by the `parser` tag of the field. Fields
+// without a tag use the name of the field, fields with the tag "-" are ignored.
+//
+//	type Pair struct {
+//	    Key   string        `parser:"Key"`
+//	    Value time.Duration `parser:"Duration"`
+//	}
+//
+// Slices collect all the children with the same type, pointers are optional and
+// remain nil if there is no child with the type. All other fields require
+// exactly one child of their type.
+//
+// Value nodes are converted to strings, integers, floats, booleans, durations
+// and encoding.TextUnmarshaler implementations. If the node has a result (see
+// Capture.Action) that is assignable to the value, the result is used instead.
+// Fields of the type *Node receive the node itself. A nil node (e.g. the
+// result of a failed parse) results in an UnmarshalError.
+func Unmarshal(n *Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{
+			Type: reflect.TypeOf(v),
+		}
+	}
+	path := rv.Elem().Type().String()
+	if n == nil {
+		return newUnmarshalError(nil, rv.Elem(), path, "nil node")
+	}
+	return unmarshal(n, rv.Elem(), path)
+}
+
+var (
+	nodeType            = reflect.TypeOf(&Node{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unmarshal stores the node in the given value, the path is the location of the
+// value within the value passed to Unmarshal.
+func unmarshal(n *Node, v reflect.Value, path string) error {
+	if v.Type() == nodeType {
+		v.Set(reflect.ValueOf(n))
+		return nil
+	}
+	if n.Result != nil && reflect.TypeOf(n.Result).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(n.Result))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshal(n, v.Elem(), path)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if n.IsParent() {
+			return newUnmarshalError(n, v, path, "expected a value node")
+		}
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(n.Value)); err != nil {
+			return &UnmarshalError{Node: n, Path: path, Type: v.Type(), Err: err}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(n, v, path)
+	case reflect.Slice:
+		v.Set(v.Slice(0, 0))
+		for i, c := range n.Children() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := unmarshal(c, elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+		return nil
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		switch {
+		case n.Result != nil:
+			v.Set(reflect.ValueOf(n.Result))
+		case n.IsParent():
+			v.Set(reflect.ValueOf(n))
+		default:
+			v.Set(reflect.ValueOf(n.Value))
+		}
+		return nil
+	}
+	return unmarshalValue(n, v, path)
+}
+
+// unmarshalStruct maps the children of the node to the fields of the struct.
+func unmarshalStruct(n *Node, v reflect.Value, path string) error {
+	children := make(map[string][]*Node)
+	for _, c := range n.Children() {
+		children[c.TypeString()] = append(children[c.TypeString()], c)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		name, ok := field.Tag.Lookup("parser")
+		if !ok || name == "" {
+			name = field.Name
+		}
+		if name == "-" {
+			continue
+		}
+
+		var (
+			nodes     = children[name]
+			fieldPath = path + "." + field.Name
+			fv        = v.Field(i)
+		)
+		switch kind := field.Type.Kind(); {
+		case kind == reflect.Slice:
+			fv.Set(reflect.MakeSlice(field.Type, 0, len(nodes)))
+			for j, c := range nodes {
+				elem := reflect.New(field.Type.Elem()).Elem()
+				if err := unmarshal(c, elem, fmt.Sprintf("%s[%d]", fieldPath, j)); err != nil {
+					return err
+				}
+				fv.Set(reflect.Append(fv, elem))
+			}
+		case len(nodes) == 0:
+			if kind == reflect.Ptr || kind == reflect.Interface {
+				// Optional.
+				continue
+			}
+			return newUnmarshalError(n, fv, fieldPath, fmt.Sprintf("missing %s node", name))
+		case 1 < len(nodes):
+			return newUnmarshalError(nodes[1], fv, fieldPath, fmt.Sprintf("expected one %s node, got %d", name, len(nodes)))
+		default:
+			if err := unmarshal(nodes[0], fv, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unmarshalValue converts the value of the node to the (scalar) value.
+func unmarshalValue(n *Node, v reflect.Value, path string) error {
+	if n.IsParent() {
+		return newUnmarshalError(n, v, path, "expected a value node")
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(n.Value)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(n.Value); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			var d time.Duration
+			if d, err = time.ParseDuration(n.Value); err == nil {
+				v.SetInt(int64(d))
+			}
+			break
+		}
+		var i int64
+		if i, err = strconv.ParseInt(n.Value, 0, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(n.Value, 0, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(n.Value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return newUnmarshalError(n, v, path, "unsupported type")
+	}
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok {
+			// The value is already part of the error message.
+			err = numErr.Err
+		}
+		return &UnmarshalError{Node: n, Path: path, Type: v.Type(), Err: err}
+	}
+	return nil
+}
+
+// UnmarshalError describes a node that could not be stored in a Go value.
+type UnmarshalError struct {
+	// Node that could not be stored.
+	Node *Node
+	// Path is the location of the Go value, e.g. Config.Servers[0].Port.
+	Path string
+	// Type of the Go value.
+	Type reflect.Type
+	// Err describes why the node could not be stored.
+	Err error
+}
+
+func newUnmarshalError(n *Node, v reflect.Value, path, message string) *UnmarshalError {
+	return &UnmarshalError{
+		Node: n,
+		Path: path,
+		Type: v.Type(),
+		Err:  errors.New(message),
+	}
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Node == nil {
+		return fmt.Sprintf("ast: can not unmarshal into %s (%s): %v", e.Path, e.Type, e.Err)
+	}
+	value := e.Node.TypeString()
+	if !e.Node.IsParent() {
+		value += fmt.Sprintf(" %q", e.Node.Value)
+	}
+	return fmt.Sprintf(
+		"ast: can not unmarshal %s [%d:%d] into %s (%s): %v",
+		value, e.Node.Start, e.Node.End, e.Path, e.Type, e.Err,
+	)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidUnmarshalError describes an invalid value passed to Unmarshal.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "ast: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return fmt.Sprintf("ast: Unmarshal(non-pointer %s)", e.Type)
+	}
+	return fmt.Sprintf("ast: Unmarshal(nil %s)", e.Type)
+}
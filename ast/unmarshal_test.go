@@ -0,0 +1,127 @@
+package ast_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"time"
+)
+
+// Config <-- Server+
+// Server <-- 'server ' Name '{' (Setting ';')* '}'
+// Setting <- 'port=' Port / 'timeout=' Timeout / 'debug=' Debug / 'alias=' Alias
+func config() ast.Capture {
+	types := []string{"UNKNOWN", "Config", "Server", "Name", "Port", "Timeout", "Debug", "Alias"}
+	value := func(typ int) ast.Capture {
+		return ast.Capture{
+			Type:        typ,
+			TypeStrings: types,
+			Value: op.MinOne(parser.CheckRuneFunc(func(r rune) bool {
+				return r != ';' && r != '{' && r != ' '
+			})),
+		}
+	}
+	return ast.Capture{
+		Type:        1,
+		TypeStrings: types,
+		Value: op.MinOne(ast.Capture{
+			Type:        2,
+			TypeStrings: types,
+			Value: op.And{
+				"server ", value(3), '{',
+				op.MinZero(op.And{
+					op.Or{
+						op.And{"port=", value(4)},
+						op.And{"timeout=", value(5)},
+						op.And{"debug=", value(6)},
+						op.And{"alias=", value(7)},
+					},
+					';',
+				}),
+				'}',
+			},
+		}),
+	}
+}
+
+type Config struct {
+	Servers []Server `parser:"Server"`
+}
+
+type Server struct {
+	Name    string
+	Port    uint16         `parser:"Port"`
+	Timeout *time.Duration `parser:"Timeout"`
+	Debug   *bool          `parser:"Debug"`
+	Aliases []string       `parser:"Alias"`
+	Node    *ast.Node      `parser:"-"`
+}
+
+func ExampleUnmarshal() {
+	p, _ := ast.New([]byte("server web{port=80;alias=www;alias=site;}server db{port=0x1538;timeout=1m30s;debug=true;}"))
+	n, _ := p.Expect(config())
+
+	var c Config
+	fmt.Println(ast.Unmarshal(n, &c))
+	for _, s := range c.Servers {
+		fmt.Println(s.Name, s.Port, s.Timeout, s.Debug != nil, s.Aliases)
+	}
+	// Output:
+	// <nil>
+	// web 80 <nil> false [www site]
+	// db 5432 1m30s true []
+}
+
+func ExampleUnmarshal_errors() {
+	for _, input := range []string{
+		"server web{port=80;}server db{port=65536;}",
+		"server web{timeout=1h;}",
+		"server web{port=80;port=81;}",
+		"server web{port=80;debug=maybe;}",
+	} {
+		p, _ := ast.New([]byte(input))
+		n, _ := p.Expect(config())
+		var c Config
+		fmt.Println(ast.Unmarshal(n, &c))
+	}
+	fmt.Println(ast.Unmarshal(nil, Config{}))
+	var c Config
+	fmt.Println(ast.Unmarshal(nil, &c))
+	// Output:
+	// ast: can not unmarshal Port "65536" [35:40] into ast_test.Config.Servers[1].Port (uint16): value out of range
+	// ast: can not unmarshal Server [0:23] into ast_test.Config.Servers[0].Port (uint16): missing Port node
+	// ast: can not unmarshal Port "81" [24:26] into ast_test.Config.Servers[0].Port (uint16): expected one Port node, got 2
+	// ast: can not unmarshal Debug "maybe" [25:30] into ast_test.Config.Servers[0].Debug (bool): invalid syntax
+	// ast: Unmarshal(non-pointer ast_test.Config)
+	// ast: can not unmarshal into ast_test.Config (ast_test.Config): nil node
+}
+
+func ExampleUnmarshal_result() {
+	p, _ := ast.New([]byte("1,2,3"))
+	n, _ := p.Expect(ast.Capture{
+		Type:        1,
+		TypeStrings: []string{"UNKNOWN", "List", "Number"},
+		Value: op.And{
+			ast.Capture{
+				Type: 2, TypeStrings: []string{"UNKNOWN", "List", "Number"},
+				Value: parser.CheckRuneRange('0', '9'),
+				Action: func(match string, _ []*ast.Node) (interface{}, error) {
+					return float64(match[0]-'0') / 2, nil
+				},
+			},
+			op.MinZero(op.And{',', ast.Capture{
+				Type: 2, TypeStrings: []string{"UNKNOWN", "List", "Number"},
+				Value: parser.CheckRuneRange('0', '9'),
+			}}),
+		},
+	})
+
+	var numbers []float64
+	fmt.Println(ast.Unmarshal(n, &numbers), numbers)
+	var values []interface{}
+	fmt.Println(ast.Unmarshal(n, &values), values)
+	// Output:
+	// <nil> [0.5 2 3]
+	// <nil> [0.5 2 3]
+}
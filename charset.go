@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// CharSet is a class that matches a set of runes. The set is represented by
+// sorted ranges of runes, so it can be inspected, combined with other sets and
+// printed. Membership of ASCII runes is checked with a bitmap.
+//
+// The zero value is an empty set. Sets never contain EOD.
+type CharSet struct {
+	// ranges are sorted, do not overlap and are not adjacent.
+	ranges []RuneRangeClass
+	// ascii contains a bit for every ASCII rune within the set.
+	ascii [2]uint64
+}
+
+// NewCharSet returns a set that contains all the runes within the given
+// (inclusive) ranges. Ranges of which the minimum is larger than the maximum
+// are ignored.
+func NewCharSet(ranges ...RuneRangeClass) CharSet {
+	return newCharSet(append([]RuneRangeClass(nil), ranges...))
+}
+
+// CharSetOf returns a set that contains the given runes.
+func CharSetOf(runes ...rune) CharSet {
+	ranges := make([]RuneRangeClass, len(runes))
+	for i, r := range runes {
+		ranges[i] = RuneRangeClass{Min: r, Max: r}
+	}
+	return newCharSet(ranges)
+}
+
+// newCharSet sorts and merges the given ranges.
+func newCharSet(ranges []RuneRangeClass) CharSet {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Min < ranges[j].Min
+	})
+	var cs CharSet
+	for _, r := range ranges {
+		if r.Min < 0 {
+			r.Min = 0
+		}
+		if unicode.MaxRune < r.Max {
+			r.Max = unicode.MaxRune
+		}
+		if r.Max < r.Min {
+			continue
+		}
+		if n := len(cs.ranges); n != 0 && r.Min <= cs.ranges[n-1].Max+1 {
+			if cs.ranges[n-1].Max < r.Max {
+				cs.ranges[n-1].Max = r.Max
+			}
+			continue
+		}
+		cs.ranges = append(cs.ranges, r)
+	}
+	for _, r := range cs.ranges {
+		for c := r.Min; c <= r.Max && c < 128; c++ {
+			cs.ascii[c/64] |= 1 << (c % 64)
+		}
+	}
+	return cs
+}
+
+// Ranges returns the sorted ranges of runes within the set.
+func (cs CharSet) Ranges() []RuneRangeClass {
+	return append([]RuneRangeClass(nil), cs.ranges...)
+}
+
+// IsEmpty returns whether the set does not contain any runes.
+func (cs CharSet) IsEmpty() bool {
+	return len(cs.ranges) == 0
+}
+
+// Contains returns whether the rune is within the set.
+func (cs CharSet) Contains(r rune) bool {
+	if 0 <= r && r < 128 {
+		return cs.ascii[r/64]&(1<<(r%64)) != 0
+	}
+	i := sort.Search(len(cs.ranges), func(i int) bool {
+		return r <= cs.ranges[i].Max
+	})
+	return i < len(cs.ranges) && cs.ranges[i].Min <= r
+}
+
+// Check checks whether the current rune of the parser is within the set.
+func (cs CharSet) Check(p *Parser) (*Cursor, bool) {
+	return p.Mark(), cs.Contains(p.Current())
+}
+
+// Union returns a set that contains the runes of both sets.
+func (cs CharSet) Union(other CharSet) CharSet {
+	ranges := make([]RuneRangeClass, 0, len(cs.ranges)+len(other.ranges))
+	ranges = append(ranges, cs.ranges...)
+	return newCharSet(append(ranges, other.ranges...))
+}
+
+// Intersect returns a set that contains the runes that are in both sets.
+func (cs CharSet) Intersect(other CharSet) CharSet {
+	var ranges []RuneRangeClass
+	for i, j := 0, 0; i < len(cs.ranges) && j < len(other.ranges); {
+		a, b := cs.ranges[i], other.ranges[j]
+		min, max := a.Min, a.Max
+		if min < b.Min {
+			min = b.Min
+		}
+		if b.Max < max {
+			max = b.Max
+		}
+		if min <= max {
+			ranges = append(ranges, RuneRangeClass{Min: min, Max: max})
+		}
+		// Continue with the range that ends first.
+		if a.Max < b.Max {
+			i++
+		} else {
+			j++
+		}
+	}
+	return newCharSet(ranges)
+}
+
+// Difference returns a set that contains the runes of the set that are not in
+// the other set.
+func (cs CharSet) Difference(other CharSet) CharSet {
+	return cs.Intersect(other.Negate())
+}
+
+// Negate returns a set that contains all the runes (up to unicode.MaxRune) that
+// are not in the set.
+func (cs CharSet) Negate() CharSet {
+	var (
+		ranges []RuneRangeClass
+		next   rune
+	)
+	for _, r := range cs.ranges {
+		if next < r.Min {
+			ranges = append(ranges, RuneRangeClass{Min: next, Max: r.Min - 1})
+		}
+		next = r.Max + 1
+	}
+	if next <= unicode.MaxRune {
+		ranges = append(ranges, RuneRangeClass{Min: next, Max: unicode.MaxRune})
+	}
+	return newCharSet(ranges)
+}
+
+// String returns the set as a PEGN class, e.g. [0-9A-Z_a-z]. Runes that are not
+// printable, or have a meaning within a class, are written as hex values.
+//
+// A hex value can not be followed by a character that extends it (e.g. x0A
+// followed by A), so the ranges that end with a hex value are written last. If
+// a hex value would still be followed by another one, the set is written as
+// multiple alternatives, e.g. [A-Z] / x7F.
+func (cs CharSet) String() string {
+	var items []classRange
+	for _, r := range cs.ranges {
+		if r.Max == r.Min+1 {
+			items = append(items, newClassRange(r.Min, r.Min), newClassRange(r.Max, r.Max))
+			continue
+		}
+		items = append(items, newClassRange(r.Min, r.Max))
+	}
+	return writeClass(items)
+}
+
+// writeClass writes the characters (or ranges) as a PEGN class, see
+// CharSet.String.
+func writeClass(items []classRange) string {
+	var hex, safe, other []classRange
+	for _, c := range items {
+		switch {
+		case c.hex:
+			hex = append(hex, c)
+		case !c.extends:
+			safe = append(safe, c)
+		default:
+			other = append(other, c)
+		}
+	}
+
+	// Values that do not extend a hex value go in between the hex values.
+	var tail []classRange
+	for _, c := range hex {
+		if len(tail) != 0 && tail[len(tail)-1].hex && c.extends && len(safe) != 0 {
+			tail, safe = append(tail, safe[len(safe)-1]), safe[:len(safe)-1]
+		}
+		tail = append(tail, c)
+	}
+
+	var (
+		classes []string
+		class   []classRange
+	)
+	write := func() {
+		if len(class) == 1 && class[0].single && class[0].hex {
+			// No need for a class, e.g. x0A.
+			classes = append(classes, class[0].value)
+			return
+		}
+		var b strings.Builder
+		b.WriteRune('[')
+		for _, c := range class {
+			b.WriteString(c.value)
+		}
+		b.WriteRune(']')
+		classes = append(classes, b.String())
+	}
+	for _, c := range append(append(safe, other...), tail...) {
+		if len(class) != 0 && class[len(class)-1].hex && c.extends {
+			write()
+			class = nil
+		}
+		class = append(class, c)
+	}
+	write()
+	return strings.Join(classes, " / ")
+}
+
+// classRange is a character, or a range of characters, within a PEGN class.
+type classRange struct {
+	value string
+	// single indicates whether it is a single character.
+	single bool
+	// extends indicates whether it would extend a preceding hex value.
+	extends bool
+	// hex indicates whether it ends with a hex value.
+	hex bool
+}
+
+func newClassRange(min, max rune) classRange {
+	value, hex := classRune(min)
+	if min != max {
+		var s string
+		s, hex = classRune(max)
+		value += "-" + s
+	}
+	first := rune(value[0])
+	return classRange{
+		value:  value,
+		single: min == max,
+		extends: 'A' <= first && first <= 'Z' || 'a' <= first && first <= 'z' ||
+			'0' <= first && first <= '9' || first == '_',
+		hex: hex,
+	}
+}
+
+// classRune returns the rune as a character within a PEGN class, or as a hex
+// value if it is not printable or has a meaning within a class.
+func classRune(r rune) (string, bool) {
+	switch {
+	case r == 'x', r == '-', r == '[', r == ']', r == '\\', r == '\'', !unicode.IsPrint(r):
+		return fmt.Sprintf("x%02X", r), true
+	default:
+		return string(r), false
+	}
+}
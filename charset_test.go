@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func ExampleCharSet() {
+	ident := parser.NewCharSet(
+		parser.CheckRuneRange('a', 'z'),
+		parser.CheckRuneRange('0', '9'),
+	).Union(parser.CharSetOf('_'))
+	fmt.Println(ident)
+
+	p, _ := parser.New([]byte("a_-"))
+	fmt.Println(p.Expect(ident))
+	fmt.Println(p.Expect(ident))
+	fmt.Println(p.Expect(ident))
+	// Output:
+	// [0-9_a-z]
+	// U+0061: a <nil>
+	// U+005F: _ <nil>
+	// <nil> parse conflict [00:002]: expected parser.CharSet [0-9_a-z] but got '-'
+}
+
+func ExampleCharSet_Difference() {
+	letters := parser.NewCharSet(parser.CheckRuneRange('a', 'z'))
+	vowels := parser.CharSetOf('a', 'e', 'i', 'o', 'u')
+	fmt.Println(letters.Difference(vowels))
+	fmt.Println(letters.Intersect(vowels).Ranges())
+	fmt.Println(vowels.Negate())
+	// Output:
+	// [b-df-hj-np-tv-z]
+	// ['a' 'e' 'i' 'o' 'u']
+	// [x00-`b-df-hj-np-tv-x10FFFF]
+}
+
+func ExampleCharSet_String() {
+	fmt.Println(parser.CharSetOf('\t', '\n', '.', 'A'))
+	// Hex values can not be followed by one another.
+	fmt.Println(parser.CharSetOf('x', '-', ']', '\n', 'A', 'a'))
+	fmt.Println(parser.CharSet{})
+	// Output:
+	// [Ax09.x0A]
+	// [Aax0A] / x2D / x5D / x78
+	// []
+}
+
+// TestCharSet compares the set operations with their definitions, for random
+// sets of runes.
+func TestCharSet(t *testing.T) {
+	random := func(r *rand.Rand) parser.CharSet {
+		var ranges []parser.RuneRangeClass
+		for i := r.Intn(6); 0 < i; i-- {
+			min := rune(r.Intn(300))
+			ranges = append(ranges, parser.CheckRuneRange(min, min+rune(r.Intn(40))))
+		}
+		return parser.NewCharSet(ranges...)
+	}
+	contains := func(cs parser.CharSet, r rune) bool {
+		for _, rr := range cs.Ranges() {
+			if rr.Min <= r && r <= rr.Max {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a, b := random(r), random(r)
+		union, intersection, difference, negation := a.Union(b), a.Intersect(b), a.Difference(b), a.Negate()
+		for _, set := range []parser.CharSet{a, union, intersection, difference, negation} {
+			ranges := set.Ranges()
+			for j := 1; j < len(ranges); j++ {
+				if ranges[j].Min <= ranges[j-1].Max+1 {
+					t.Fatalf("%s: ranges are not merged", set)
+				}
+			}
+		}
+		for c := rune(0); c < 400; c++ {
+			ina, inb := a.Contains(c), b.Contains(c)
+			if ina != contains(a, c) {
+				t.Fatalf("%s: contains %q", a, c)
+			}
+			if union.Contains(c) != (ina || inb) {
+				t.Fatalf("%s | %s: %q", a, b, c)
+			}
+			if intersection.Contains(c) != (ina && inb) {
+				t.Fatalf("%s & %s: %q", a, b, c)
+			}
+			if difference.Contains(c) != (ina && !inb) {
+				t.Fatalf("%s - %s: %q", a, b, c)
+			}
+			if negation.Contains(c) == ina {
+				t.Fatalf("!%s: %q", a, c)
+			}
+		}
+		if a.Negate().Negate().String() != a.String() {
+			t.Fatalf("%s: double negation", a)
+		}
+	}
+
+	all := parser.CharSet{}.Negate()
+	if !all.Contains(unicode.MaxRune) || all.Contains(parser.EOD) || all.Contains(-1) {
+		t.Error(all)
+	}
+}
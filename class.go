@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"github.com/di-wu/parser/op"
 	"strconv"
 	"strings"
@@ -9,97 +10,189 @@ import (
 
 // AnonymousClass represents an anonymous Class.Check function.
 //
+// Note that the Check* functions (e.g. CheckRune) return class values that can
+// describe themselves instead, their Check method is an AnonymousClass.
+//
 // The cursor should never be nil except if it fails at the first rune.
 // e.g. "121".Check("123") should return a mark to the 2nd value.
 type AnonymousClass func(p *Parser) (*Cursor, bool)
 
-// CheckInteger returns an AnonymousClass that checks whether the following
-// runes are equal to the given integer. It also consumes leading zeros
-// when indicated to do so.
-func CheckInteger(i int, leadingZeros bool) AnonymousClass {
+// CheckInteger returns a class that checks whether the following runes are
+// equal to the given integer. It also consumes leading zeros when indicated to
+// do so.
+func CheckInteger(i int, leadingZeros bool) IntegerClass {
+	return IntegerClass{
+		Value:        i,
+		LeadingZeros: leadingZeros,
+	}
+}
+
+// IntegerClass is a class that matches an integer, see CheckInteger.
+type IntegerClass struct {
+	Value        int
+	LeadingZeros bool
+}
+
+// Check checks whether the following runes are equal to the integer.
+func (c IntegerClass) Check(p *Parser) (*Cursor, bool) {
 	// Edge case: i == 0.
-	if i == 0 {
+	if c.Value == 0 {
 		// Consume all zeroes if leadingZeros == true.
-		if leadingZeros {
-			return func(p *Parser) (*Cursor, bool) {
-				return p.Check(op.MinOne('0'))
-			}
-		}
-		return func(p *Parser) (*Cursor, bool) {
-			return p.Check('0')
+		if c.LeadingZeros {
+			return p.Check(op.MinOne('0'))
 		}
+		return p.Check('0')
 	}
 
 	var and op.And
-	str := strconv.Itoa(i)
+	str := strconv.Itoa(c.Value)
 	// Negative integers.
-	if i < 0 {
+	if c.Value < 0 {
 		and = append(and, '-')
 		str = strings.TrimPrefix(str, "-")
 	}
 	// Leading zeroes.
-	if leadingZeros {
+	if c.LeadingZeros {
 		// Consume all leading zeros.
 		and = append(and, op.MinZero('0'))
 	}
-	return func(p *Parser) (*Cursor, bool) {
-		return p.Check(append(and, str))
-	}
+	return p.Check(append(and, str))
+}
+
+func (c IntegerClass) String() string {
+	return fmt.Sprintf("integer %d", c.Value)
 }
 
-// CheckIntegerRange returns an AnonymousClass that checks whether the following
-// runes are inside the given range (inclusive). It also consumes leading zeros
-// when indicated to do so.
+// CheckIntegerRange returns a class that checks whether the following runes are
+// inside the given range (inclusive). It also consumes leading zeros when
+// indicated to do so.
 //
 // Note that this check consumes all the sequential numbers it possibly can.
 // e.g. "12543" is not in the range (0, 12345), even the prefix "1254" is.
-func CheckIntegerRange(min, max uint, leadingZeros bool) AnonymousClass {
-	return func(p *Parser) (*Cursor, bool) {
-		digit := CheckRuneRange('0', '9')
-		check := CheckRuneRange('1', '9')
-		if leadingZeros {
-			check = digit
-		}
+func CheckIntegerRange(min, max uint, leadingZeros bool) IntegerRangeClass {
+	return IntegerRangeClass{
+		Min:          min,
+		Max:          max,
+		LeadingZeros: leadingZeros,
+	}
+}
 
-		var last *Cursor
-		var str string
-		for r, ok := p.Check(check); ok; r, ok = p.Check(digit) {
-			str += string(r.Rune)
-			last = r
-		}
-		i, _ := strconv.Atoi(str)
-		if i := uint(i); min <= i && i <= max {
-			return last, true
-		}
+// IntegerRangeClass is a class that matches an integer within a range
+// (inclusive), see CheckIntegerRange.
+type IntegerRangeClass struct {
+	Min, Max     uint
+	LeadingZeros bool
+}
+
+// Check checks whether the following runes are inside the range.
+func (c IntegerRangeClass) Check(p *Parser) (*Cursor, bool) {
+	digit := CheckRuneRange('0', '9')
+	check := CheckRuneRange('1', '9')
+	if c.LeadingZeros {
+		check = digit
+	}
+
+	var last *Cursor
+	var str string
+	for r, ok := p.Check(check); ok; r, ok = p.Check(digit) {
+		str += string(r.Rune)
+		last = r
+	}
+	// Integers that overflow are out of range.
+	i, err := strconv.ParseUint(str, 10, strconv.IntSize)
+	if err != nil {
 		return nil, false
 	}
+	if i := uint(i); c.Min <= i && i <= c.Max {
+		return last, true
+	}
+	return nil, false
 }
 
-// CheckRune returns an AnonymousClass that checks whether the current rune of
-// the parser matches the given rune. The same result can be achieved by using
-// p.Expect(r). Where 'p' is a reference to the parser an 'r' a rune value.
-func CheckRune(expected rune) AnonymousClass {
-	return CheckRuneFunc(func(actual rune) bool {
-		return expected == actual
-	})
+func (c IntegerRangeClass) String() string {
+	return fmt.Sprintf("integer [%d, %d]", c.Min, c.Max)
 }
 
-// CheckRuneCI returns an AnonymousClass that checks whether the current (lower
-// cased) rune of the parser matches the given (lower cased) rune. The given
-// rune does not need to be lower case.
-func CheckRuneCI(expected rune) AnonymousClass {
-	expected = unicode.ToLower(expected)
-	return CheckRuneFunc(func(actual rune) bool {
-		return expected == unicode.ToLower(actual)
-	})
+// CheckRune returns a class that checks whether the current rune of the parser
+// matches the given rune. The same result can be achieved by using p.Expect(r).
+// Where 'p' is a reference to the parser an 'r' a rune value.
+func CheckRune(expected rune) RuneClass {
+	return RuneClass{
+		Rune: expected,
+	}
+}
+
+// CheckRuneCI returns a class that checks whether the current (lower cased)
+// rune of the parser matches the given (lower cased) rune. The given rune does
+// not need to be lower case.
+func CheckRuneCI(expected rune) RuneClass {
+	return RuneClass{
+		Rune:            expected,
+		CaseInsensitive: true,
+	}
 }
 
-// CheckRuneRange returns an AnonymousClass that checks whether the current rune of
-// the parser is inside the given range (inclusive).
-func CheckRuneRange(min, max rune) AnonymousClass {
-	return CheckRuneFunc(func(r rune) bool {
-		return min <= r && r <= max
-	})
+// RuneClass is a class that matches a single rune, see CheckRune and
+// CheckRuneCI.
+type RuneClass struct {
+	Rune rune
+	// CaseInsensitive indicates whether the lower cased runes get compared.
+	CaseInsensitive bool
+}
+
+// Check checks whether the current rune of the parser matches the rune.
+func (c RuneClass) Check(p *Parser) (*Cursor, bool) {
+	if c.CaseInsensitive {
+		return p.Mark(), unicode.ToLower(c.Rune) == unicode.ToLower(p.Current())
+	}
+	return p.Mark(), c.Rune == p.Current()
+}
+
+func (c RuneClass) String() string {
+	if c.CaseInsensitive {
+		return fmt.Sprintf("%qi", c.Rune)
+	}
+	return fmt.Sprintf("%q", c.Rune)
+}
+
+// CheckRuneRange returns a class that checks whether the current rune of the
+// parser is inside the given range (inclusive).
+func CheckRuneRange(min, max rune) RuneRangeClass {
+	return RuneRangeClass{
+		Min: min,
+		Max: max,
+	}
+}
+
+// RuneRangeClass is a class that matches a rune within a range (inclusive), see
+// CheckRuneRange.
+type RuneRangeClass struct {
+	Min, Max rune
+}
+
+// Check checks whether the current rune of the parser is inside the range.
+func (c RuneRangeClass) Check(p *Parser) (*Cursor, bool) {
+	r := p.Current()
+	return p.Mark(), c.Min <= r && r <= c.Max
+}
+
+func (c RuneRangeClass) String() string {
+	if c.Min == c.Max {
+		return fmt.Sprintf("%q", c.Min)
+	}
+	return fmt.Sprintf("%q-%q", c.Min, c.Max)
+}
+
+// AnyRune is a class that matches any rune, except for the end of the data.
+type AnyRune struct{}
+
+// Check checks whether the parser is not at the end of the data.
+func (AnyRune) Check(p *Parser) (*Cursor, bool) {
+	return p.Mark(), p.Current() != EOD
+}
+
+func (AnyRune) String() string {
+	return "any"
 }
 
 // CheckRuneFunc returns an AnonymousClass that checks whether the current rune of
@@ -110,40 +203,56 @@ func CheckRuneFunc(f func(r rune) bool) AnonymousClass {
 	}
 }
 
-// CheckString returns an AnonymousClass that checks whether the current
-// sequence runes of the parser matches the given string. The same result can be
-// achieved by using p.Expect(s). Where 'p' is a reference to the parser an 's'
-// a string value.
-func CheckString(s string) AnonymousClass {
-	return func(p *Parser) (*Cursor, bool) {
-		var last *Cursor
-		for _, r := range []rune(s) {
-			if p.Current() != r {
-				return last, false
-			}
-			last = p.Mark()
-			p.Next()
-		}
-		return last, true
+// CheckString returns a class that checks whether the current sequence runes
+// of the parser matches the given string. The same result can be achieved by
+// using p.Expect(s). Where 'p' is a reference to the parser an 's' a string
+// value.
+func CheckString(s string) StringClass {
+	return StringClass{
+		Value: s,
 	}
 }
 
-// CheckStringCI returns an AnonymousClass that checks whether the current
-// (lower cased) sequence runes of the parser matches the given (lower cased)
-// string. The given string does not need to be lower case.
-func CheckStringCI(s string) AnonymousClass {
-	s = strings.ToLower(s)
-	return func(p *Parser) (*Cursor, bool) {
-		var last *Cursor
-		for _, r := range []rune(s) {
-			if unicode.ToLower(p.Current()) != r {
-				return last, false
-			}
-			last = p.Mark()
-			p.Next()
+// CheckStringCI returns a class that checks whether the current (lower cased)
+// sequence runes of the parser matches the given (lower cased) string. The
+// given string does not need to be lower case.
+func CheckStringCI(s string) StringClass {
+	return StringClass{
+		Value:           s,
+		CaseInsensitive: true,
+	}
+}
+
+// StringClass is a class that matches a sequence of runes, see CheckString and
+// CheckStringCI.
+type StringClass struct {
+	Value string
+	// CaseInsensitive indicates whether the lower cased runes get compared.
+	CaseInsensitive bool
+}
+
+// Check checks whether the current sequence of runes matches the string.
+func (c StringClass) Check(p *Parser) (*Cursor, bool) {
+	var last *Cursor
+	for _, r := range []rune(c.Value) {
+		current := p.Current()
+		if c.CaseInsensitive {
+			r, current = unicode.ToLower(r), unicode.ToLower(current)
 		}
-		return last, true
+		if current != r {
+			return last, false
+		}
+		last = p.Mark()
+		p.Next()
+	}
+	return last, true
+}
+
+func (c StringClass) String() string {
+	if c.CaseInsensitive {
+		return fmt.Sprintf("%qi", c.Value)
 	}
+	return fmt.Sprintf("%q", c.Value)
 }
 
 // Class provides an interface for checking classes.
@@ -47,6 +47,18 @@ func ExampleCheckIntegerRange() {
 	// U+0032: 2 true
 }
 
+func ExampleRuneRangeClass() {
+	// The classes are values, so their parameters can be inspected.
+	digit := parser.CheckRuneRange('0', '9')
+	fmt.Printf("%c-%c\n", digit.Min, digit.Max)
+
+	p, _ := parser.New([]byte("7"))
+	fmt.Println(p.Check(digit))
+	// Output:
+	// 0-9
+	// U+0037: 7 true
+}
+
 func TestCheckIntegerRange(t *testing.T) {
 	p := func(i int) *parser.Parser {
 		p, _ := parser.New([]byte(strconv.Itoa(i)))
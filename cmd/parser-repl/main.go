@@ -0,0 +1,46 @@
+// Command parser-repl loads a PEGN grammar and parses the lines you type with
+// one of its rules. It shows the resulting tree, the syntax error or a trace of
+// the attempted rules and alternatives.
+//
+// Usage:
+//
+//	parser-repl [-rule name] [-trace] grammar.pegn
+//
+// Lines that end with a backslash are continued on the next line. Lines that
+// start with a colon are commands, type :help to list them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	rule := flag.String("rule", "", "the rule to start with, defaults to the first rule")
+	trace := flag.Bool("trace", false, "trace the attempted rules and alternatives")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: parser-repl [-rule name] [-trace] grammar.pegn")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	r := &repl{
+		path:  flag.Arg(0),
+		rule:  *rule,
+		trace: *trace,
+		out:   os.Stdout,
+	}
+	if err := r.load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := r.run(os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"github.com/di-wu/parser/pegn"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const help = `commands:
+  :rule [name]  switch to the given rule, or show the current one
+  :rules        list all the rules
+  :reload       reload the grammar file
+  :trace        toggle tracing
+  :help         show this message
+  :quit         exit`
+
+type repl struct {
+	path  string
+	rule  string
+	trace bool
+	out   io.Writer
+
+	g *grammar.Grammar
+}
+
+// load (re)loads the grammar file, and the grammars it imports. The current
+// rule is kept if it still exists.
+func (r *repl) load() error {
+	g, err := pegn.CompileFile(r.path)
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.path, err)
+	}
+	rules := g.Rules()
+	if len(rules) == 0 {
+		return fmt.Errorf("%s: no rules defined", r.path)
+	}
+	// Start with the first rule of the file itself, not of an imported grammar.
+	first := rules[0].Name
+	for _, rule := range rules {
+		if !strings.Contains(rule.Name, ".") {
+			first = rule.Name
+			break
+		}
+	}
+	switch {
+	case r.rule == "":
+		r.rule = first
+	case g.Rule(r.rule) == nil:
+		if r.g == nil {
+			return fmt.Errorf("%s: rule %s is not defined", r.path, r.rule)
+		}
+		fmt.Fprintf(r.out, "rule %s no longer exists, switched to %s\n", r.rule, first)
+		r.rule = first
+	}
+	r.g = g
+	return nil
+}
+
+// run reads lines from the given reader until it is exhausted or the user
+// quits.
+func (r *repl) run(in io.Reader) error {
+	var (
+		scanner = bufio.NewScanner(in)
+		input   []string
+	)
+	r.prompt(input)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, `\`) {
+			input = append(input, strings.TrimSuffix(line, `\`))
+			r.prompt(input)
+			continue
+		}
+		if len(input) == 0 && strings.HasPrefix(line, ":") {
+			if quit := r.command(line); quit {
+				return nil
+			}
+		} else {
+			r.parse(strings.Join(append(input, line), "\n"))
+			input = nil
+		}
+		r.prompt(input)
+	}
+	return scanner.Err()
+}
+
+func (r *repl) prompt(input []string) {
+	if len(input) != 0 {
+		fmt.Fprint(r.out, strings.Repeat(" ", len(r.rule)), "| ")
+		return
+	}
+	fmt.Fprint(r.out, r.rule, "> ")
+}
+
+// command executes the given command, returns true if the user wants to quit.
+func (r *repl) command(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":rule":
+		if len(fields) == 1 {
+			fmt.Fprintln(r.out, r.rule)
+			break
+		}
+		if r.g.Rule(fields[1]) == nil {
+			fmt.Fprintf(r.out, "rule %s is not defined\n", fields[1])
+			break
+		}
+		r.rule = fields[1]
+	case ":rules":
+		for _, rule := range r.g.Rules() {
+			operator := "<-"
+			if rule.Capture {
+				operator = "<--"
+			}
+			fmt.Fprintln(r.out, rule.Name, operator)
+		}
+	case ":reload":
+		if err := r.load(); err != nil {
+			fmt.Fprintln(r.out, err)
+			break
+		}
+		fmt.Fprintf(r.out, "reloaded %s\n", r.path)
+	case ":trace":
+		r.trace = !r.trace
+		if r.trace {
+			fmt.Fprintln(r.out, "tracing enabled")
+		} else {
+			fmt.Fprintln(r.out, "tracing disabled")
+		}
+	case ":help":
+		fmt.Fprintln(r.out, help)
+	case ":quit", ":q":
+		return true
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, type :help to list all commands\n", fields[0])
+	}
+	return false
+}
+
+// parse parses the given input with the current rule and prints the result.
+func (r *repl) parse(input string) {
+	if input == "" {
+		return
+	}
+	p, err := ast.New([]byte(input))
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	rule, err := r.g.Value(r.rule)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	o := &observer{out: r.out, trace: r.trace, input: input}
+	p.SetObserver(o)
+	node, err := p.Expect(rule)
+	if err == nil {
+		_, err = p.Expect(parser.EOD)
+	}
+	if err != nil {
+		o.report()
+		return
+	}
+	if node == nil {
+		fmt.Fprintln(r.out, "matched, no nodes captured")
+		return
+	}
+	printTree(r.out, node, "")
+}
+
+// printTree prints the node and its children, one node per line.
+func printTree(w io.Writer, n *ast.Node, indent string) {
+	if !n.IsParent() {
+		fmt.Fprintf(w, "%s%s %q\n", indent, n.TypeString(), n.Value)
+		return
+	}
+	fmt.Fprintf(w, "%s%s\n", indent, n.TypeString())
+	for _, c := range n.Children() {
+		printTree(w, c, indent+"  ")
+	}
+}
+
+// frame is a value the parser is trying to match.
+type frame struct {
+	value interface{}
+	// start is the offset at which the frame was entered.
+	start int
+	// printed indicates whether the frame shows up in the trace.
+	printed bool
+	// rule is the innermost rule of the frame.
+	rule string
+	// alternative is the (one based) index of the frame within its parent,
+	// if it is an alternative of an op.Or or op.XOr.
+	alternative int
+	// alternatives is the number of entered alternatives of an op.Or or
+	// op.XOr.
+	alternatives int
+}
+
+// observer traces the parser and keeps track of the furthest parse error.
+type observer struct {
+	out   io.Writer
+	trace bool
+	input string
+
+	stack  []*frame
+	depth  int
+	offset int
+	// expected contains the descriptions of the values that were expected at
+	// the furthest offset.
+	expected []string
+}
+
+func (o *observer) Enter(i interface{}, at *parser.Cursor) {
+	f := &frame{value: i, start: at.Offset()}
+	var parent *frame
+	if len(o.stack) != 0 {
+		parent = o.stack[len(o.stack)-1]
+		f.rule = parent.rule
+	}
+	name, isRule := ast.RuleName(i)
+	if isRule {
+		f.rule = name
+	}
+	if o.trace {
+		switch {
+		case isRule:
+			f.printed = true
+			o.printf("%s %s", name, o.position(at.Offset()))
+		case parent != nil && isAlternation(parent.value):
+			parent.alternatives++
+			f.printed = true
+			f.alternative = parent.alternatives
+			o.printf("alternative %d: %s", f.alternative, describe(i))
+		}
+	}
+	if f.printed {
+		o.depth++
+	}
+	o.stack = append(o.stack, f)
+}
+
+func (o *observer) Exit(i interface{}, at *parser.Cursor, _ *ast.Node, err error) {
+	if len(o.stack) == 0 {
+		return
+	}
+	f := o.stack[len(o.stack)-1]
+	o.stack = o.stack[:len(o.stack)-1]
+	if err != nil {
+		o.fail(f, at, err)
+	}
+	if !f.printed {
+		return
+	}
+	o.depth--
+	name := fmt.Sprintf("alternative %d", f.alternative)
+	if n, ok := ast.RuleName(i); ok {
+		name = n
+	}
+	if err != nil {
+		o.printf("%s failed", name)
+		return
+	}
+	o.printf("%s matched %q", name, o.input[f.start:at.Offset()])
+}
+
+// fail records the given error if it occurred at the furthest offset.
+func (o *observer) fail(f *frame, at *parser.Cursor, err error) {
+	e, ok := err.(*parser.ExpectedParseError)
+	if !ok {
+		return
+	}
+	var (
+		offset   = e.Conflict.Offset()
+		expected string
+	)
+	switch v := e.Expected.(type) {
+	case rune:
+		if v == parser.EOD {
+			expected = "end of input"
+		} else {
+			expected = strconv.QuoteRune(v)
+		}
+	case string:
+		expected = strconv.Quote(v)
+	case parser.CharSet, parser.UnicodeClass, *unicode.RangeTable, op.Quoted:
+		expected = parser.Stringer(v)
+	case parser.AnonymousClass, parser.Class:
+		// The conflict of a class points to the rune after the one that did
+		// not match, the parser got reset to the latter.
+		offset = at.Offset()
+		expected = f.rule
+	default:
+		return
+	}
+	if offset < o.offset {
+		return
+	}
+	if o.offset < offset {
+		o.offset = offset
+		o.expected = nil
+	}
+	if expected == "" {
+		return
+	}
+	for _, e := range o.expected {
+		if e == expected {
+			return
+		}
+	}
+	o.expected = append(o.expected, expected)
+}
+
+// report prints the furthest error, with a caret pointing to its location.
+func (o *observer) report() {
+	var (
+		start = strings.LastIndex(o.input[:o.offset], "\n") + 1
+		end   = strings.Index(o.input[o.offset:], "\n")
+	)
+	if end == -1 {
+		end = len(o.input)
+	} else {
+		end += o.offset
+	}
+	got := "end of input"
+	if o.offset < len(o.input) {
+		r, _ := utf8.DecodeRuneInString(o.input[o.offset:])
+		got = strconv.QuoteRune(r)
+	}
+	message := fmt.Sprintf("syntax error at %s: unexpected %s", o.position(o.offset), got)
+	switch len(o.expected) {
+	case 0:
+	case 1:
+		message += ", expected " + o.expected[0]
+	default:
+		message += fmt.Sprintf(
+			", expected %s or %s",
+			strings.Join(o.expected[:len(o.expected)-1], ", "),
+			o.expected[len(o.expected)-1],
+		)
+	}
+	fmt.Fprintln(o.out, message)
+	fmt.Fprintln(o.out, "  "+o.input[start:end])
+	fmt.Fprintln(o.out, "  "+strings.Repeat(" ", utf8.RuneCountInString(o.input[start:o.offset]))+"^")
+}
+
+// position returns the line and column of the given offset.
+func (o *observer) position(offset int) string {
+	before := o.input[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return fmt.Sprintf("%d:%d", line, column)
+}
+
+func (o *observer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(o.out, "%s%s\n", strings.Repeat("  ", o.depth), fmt.Sprintf(format, args...))
+}
+
+func isAlternation(i interface{}) bool {
+	switch i.(type) {
+	case op.Or, op.XOr:
+		return true
+	default:
+		return false
+	}
+}
+
+// describe returns a short string representation of the given value.
+func describe(i interface{}) string {
+	if name, ok := ast.RuleName(i); ok {
+		return name
+	}
+	switch v := ast.ConvertAliases(i).(type) {
+	case ast.Capture:
+		return describe(v.Value)
+	case op.Not:
+		return "!" + describe(v.Value)
+	case op.Ensure:
+		return "&" + describe(v.Value)
+	case op.Named:
+		return fmt.Sprintf("(?<%s>%s)", v.Name, describe(v.Value))
+	case op.BackRef:
+		return fmt.Sprintf("\\k<%s>", v.Name)
+	case op.Cut:
+		return "~"
+	case op.Expect:
+		return describe(v.Value)
+	case op.Token:
+		return describe(v.Value)
+	case op.And:
+		return describeAll(" ", v)
+	case op.Or:
+		return "(" + describeAll(" / ", v) + ")"
+	case op.XOr:
+		return "(" + describeAll(" ^ ", v) + ")"
+	case op.Range:
+		s := describe(v.Value)
+		if _, ok := v.Value.(op.And); ok {
+			s = "(" + s + ")"
+		}
+		switch {
+		case v.Min == 0 && v.Max == -1:
+			return s + "*"
+		case v.Min == 1 && v.Max == -1:
+			return s + "+"
+		case v.Min == 0 && v.Max == 1:
+			return s + "?"
+		case v.Max == -1:
+			return fmt.Sprintf("%s{%d,}", s, v.Min)
+		case v.Min == v.Max:
+			return fmt.Sprintf("%s{%d}", s, v.Min)
+		}
+		return fmt.Sprintf("%s{%d,%d}", s, v.Min, v.Max)
+	case rune:
+		return strconv.QuoteRune(v)
+	case string:
+		return strconv.Quote(v)
+	default:
+		return "class"
+	}
+}
+
+func describeAll(sep string, values []interface{}) string {
+	all := make([]string, len(values))
+	for i, v := range values {
+		all[i] = describe(v)
+	}
+	return strings.Join(all, sep)
+}
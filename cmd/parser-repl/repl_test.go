@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const calc = `Expr    <-- Integer (Op Integer)*
+Op      <-- '+' / '-'
+Integer <-- [0-9]+ / '(' Expr ')'
+`
+
+func TestREPL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parser-repl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "calc.pegn")
+	if err := ioutil.WriteFile(path, []byte(calc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r := &repl{path: path, out: &out}
+	if err := r.load(); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input, output string
+	}{
+		{
+			input: "1+2",
+			output: `Expr
+  Integer "1"
+  Op "+"
+  Integer "2"`,
+		},
+		{
+			input: "1+",
+			output: `syntax error at 1:3: unexpected end of input, expected Integer or '('
+  1+
+    ^`,
+		},
+		{
+			input: "1\\\n+x",
+			output: `syntax error at 1:2: unexpected '\n', expected Integer, '+', '-' or end of input
+  1
+   ^`,
+		},
+		{
+			input:  ":rule Op\n-",
+			output: `Op "-"`,
+		},
+		{
+			input: ":trace\n+",
+			output: `tracing enabled
+Op 1:1
+  alternative 1: '+'
+  alternative 1 matched "+"
+Op matched "+"
+Op "+"`,
+		},
+		{
+			input:  ":trace\n:rule Nope\n:rule",
+			output: "tracing disabled\nrule Nope is not defined\nOp",
+		},
+	} {
+		out.Reset()
+		if err := r.run(strings.NewReader(test.input)); err != nil {
+			t.Fatal(err)
+		}
+		// Remove the prompts.
+		output := strings.NewReplacer("Expr> ", "", "Op> ", "", "    | ", "").Replace(out.String())
+		if strings.TrimSpace(output) != test.output {
+			t.Errorf("%q:\n%s", test.input, output)
+		}
+	}
+
+	// Remove the current rule from the grammar.
+	if err := ioutil.WriteFile(path, []byte("Bit <-- '0' / '1'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := r.run(strings.NewReader(":reload\n1\n:quit\n0")); err != nil {
+		t.Fatal(err)
+	}
+	if output := out.String(); output != "Op> rule Op no longer exists, switched to Bit\nreloaded "+path+"\nBit> Bit \"1\"\nBit> " {
+		t.Errorf("%q", output)
+	}
+}
@@ -0,0 +1,15 @@
+// Command pegn-lsp is a language server for PEGN grammars. It communicates over
+// stdin and stdout, which is what most editors expect.
+package main
+
+import (
+	"github.com/di-wu/parser/lsp"
+	"log"
+	"os"
+)
+
+func main() {
+	if err := lsp.NewServer(lsp.PEGN()).Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+// Command pegn-railroad generates railroad diagrams of a PEGN grammar. It
+// writes an SVG image per rule and an index page (index.html) to the output
+// directory.
+//
+// Usage:
+//
+//	pegn-railroad [-o dir] grammar.pegn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/di-wu/parser/pegn"
+	"github.com/di-wu/parser/railroad"
+	"os"
+)
+
+func main() {
+	out := flag.String("o", ".", "the directory to write the diagrams to")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: pegn-railroad [-o dir] grammar.pegn")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	g, err := pegn.CompileFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := railroad.WriteFiles(*out, g.Rules()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
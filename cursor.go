@@ -14,6 +14,10 @@ type Cursor struct {
 	position int
 	// The row and column of the current rune, NOT in bytes!
 	row, column int
+	// The indentation levels, see op.Indent.
+	indent *indentation
+	// The values set by the user, see Parser.SetValue.
+	values *values
 }
 
 // Position returns the row and column of the cursors location.
@@ -21,6 +25,11 @@ func (c *Cursor) Position() (int, int) {
 	return c.row, c.column
 }
 
+// Offset returns the position of the cursor in the buffer, in bytes.
+func (c *Cursor) Offset() int {
+	return c.position
+}
+
 func (c *Cursor) String() string {
 	return fmt.Sprintf("%U: %c", c.Rune, c.Rune)
 }
@@ -40,8 +49,13 @@ func (s *state) Ok(last *Cursor) {
 	}
 	s.end = last
 	// We jump to the given cursor (last parsed rune) because it is not
-	// guaranteed that the already parser did not pass it.
+	// guaranteed that the already parser did not pass it. The indentation
+	// levels and values might have changed after the last parsed rune, so
+	// those are kept.
+	current := s.p.cursor
 	s.p.Jump(last).Next()
+	s.p.cursor.indent = current.indent
+	s.p.cursor.values = current.values
 }
 
 // End returns a mark to the last successfully parsed rune.
@@ -0,0 +1,170 @@
+package ebnf
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"strconv"
+	"unicode"
+)
+
+// Compile parses the given EBNF grammar and converts it to a grammar that can
+// be used to parse data directly. Every production produces a node.
+//
+// EBNF does not define the order in which alternatives are tried, they are
+// tried in the order they are written and the first one that matches wins.
+func Compile(data []byte) (*grammar.Grammar, error) {
+	n, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return CompileNode(n, data)
+}
+
+// CompileNode converts the given (parsed) EBNF grammar to a grammar. The data
+// is used to report the positions of errors.
+func CompileNode(n *ast.Node, data []byte) (*grammar.Grammar, error) {
+	var (
+		g       = grammar.New()
+		defined = make(map[string]bool)
+	)
+	for _, prod := range n.Children() {
+		if defined[prod.FirstChild.Value] {
+			return nil, grammar.NewImportError("ebnf", data, prod.Start, "symbol %s is already defined", prod.FirstChild.Value)
+		}
+		defined[prod.FirstChild.Value] = true
+	}
+	c := compiler{data: data, defined: defined}
+	for _, prod := range n.Children() {
+		value, err := c.compile(prod.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		_ = g.Add(&grammar.Rule{
+			Name:    prod.FirstChild.Value,
+			Capture: true,
+			Value:   value,
+		})
+	}
+	return g, nil
+}
+
+type compiler struct {
+	data []byte
+	// defined contains the symbols of all the productions of the grammar.
+	defined map[string]bool
+}
+
+// error returns an error at the start of the given node.
+func (c compiler) error(n *ast.Node, format string, a ...interface{}) error {
+	return grammar.NewImportError("ebnf", c.data, n.Start, format, a...)
+}
+
+func (c compiler) compile(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case ChoiceType, SequenceType:
+		values, err := c.compileAll(n.Children())
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == ChoiceType {
+			return op.Or(values), nil
+		}
+		return op.And(values), nil
+	case DifferenceType:
+		values, err := c.compileAll(n.Children())
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		// A - B: matches A, but not B.
+		return op.And{op.Not{Value: values[1]}, values[0]}, nil
+	case ItemType:
+		v, err := c.compile(n.FirstChild)
+		if err != nil || n.FirstChild == n.LastChild {
+			return v, err
+		}
+		switch n.LastChild.Value {
+		case "?":
+			return op.Optional(v), nil
+		case "*":
+			return op.MinZero(v), nil
+		default:
+			return op.MinOne(v), nil
+		}
+	case SymbolType:
+		if !c.defined[n.Value] {
+			return nil, c.error(n, "undefined symbol %s", n.Value)
+		}
+		return grammar.Ref{Name: n.Value}, nil
+	case StringType:
+		if r := []rune(n.Value); len(r) == 1 {
+			return r[0], nil
+		}
+		return n.Value, nil
+	case ClassType, NegatedClassType:
+		var or op.Or
+		for _, child := range n.Children() {
+			v, err := c.compile(child)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, v)
+		}
+		var class interface{} = or
+		if len(or) == 1 {
+			class = or[0]
+		}
+		if n.Type == NegatedClassType {
+			return op.And{op.Not{Value: class}, parser.AnyRune{}}, nil
+		}
+		return class, nil
+	case RangeType:
+		min, err := c.rune(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		max, err := c.rune(n.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, c.error(n, "invalid range %s", c.data[n.Start:n.End])
+		}
+		return parser.CheckRuneRange(min, max), nil
+	case CharCodeType, CharType:
+		return c.rune(n)
+	default:
+		return nil, c.error(n, "unexpected node %s", n.TypeString())
+	}
+}
+
+func (c compiler) compileAll(nodes []*ast.Node) ([]interface{}, error) {
+	var values []interface{}
+	for _, n := range nodes {
+		v, err := c.compile(n)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// rune returns the rune of a Char or CharCode node.
+func (c compiler) rune(n *ast.Node) (rune, error) {
+	if n.Type == CharCodeType {
+		v, err := strconv.ParseUint(n.Value[2:], 16, 32)
+		if err != nil || unicode.MaxRune < v {
+			return 0, c.error(n, "invalid character code %s", n.Value)
+		}
+		return rune(v), nil
+	}
+	return []rune(n.Value)[0], nil
+}
@@ -0,0 +1,108 @@
+package ebnf_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/ebnf"
+	"testing"
+)
+
+func ExampleCompile() {
+	g, err := ebnf.Compile([]byte(`
+/* From the XML specification. */
+[4]  NameStartChar ::= ":" | [A-Z] | "_" | [a-z] | [#xC0-#xD6] | [#xD8-#xF6]
+[4a] NameChar      ::= NameStartChar | "-" | "." | [0-9] | #xB7
+[5]  Name          ::= NameStartChar (NameChar)*
+[10] AttValue      ::= '"' ([^<&"])* '"'
+                     | "'" ([^<&'])* "'"
+[15] Comment       ::= '<!--' ((Char - '-') | ('-' (Char - '-')))* '-->'
+[2]  Char          ::= #x9 | #xA | #xD | [#x20-#xD7FF] | [#xE000-#xFFFD] | [#x10000-#x10FFFF]
+`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(g.Parse("Name", []byte("xml:lang")))
+	fmt.Println(g.Parse("AttValue", []byte(`"é"`)))
+	fmt.Println(g.Parse("Comment", []byte("<!-- a - b -->")))
+	_, err = g.Parse("Comment", []byte("<!-- a -- b -->"))
+	fmt.Println(err != nil)
+	// Output:
+	// ["Name",[["NameStartChar","x"],["NameStartChar","m"],["NameStartChar","l"],["NameStartChar",":"],["NameStartChar","l"],["NameStartChar","a"],["NameStartChar","n"],["NameStartChar","g"]]] <nil>
+	// ["AttValue","\"é\""] <nil>
+	// ["Comment",[["Char"," "],["Char","a"],["Char"," "],["Char"," "],["Char","b"],["Char"," "]]] <nil>
+	// true
+}
+
+func TestCompile_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{
+			grammar: "A ::= 'a'\nA ::= 'b'\n",
+			err:     "ebnf [01:000]: symbol A is already defined",
+		},
+		{
+			grammar: "A ::= 'a' B\n  | C\n",
+			err:     "ebnf [00:010]: undefined symbol B",
+		},
+		{
+			grammar: "A ::= [a-z#x7A-#x61]\n",
+			err:     "ebnf [00:010]: invalid range #x7A-#x61",
+		},
+		{
+			grammar: "A ::= #x110000\n",
+			err:     "ebnf [00:006]: invalid character code #x110000",
+		},
+	} {
+		if _, err := ebnf.Compile([]byte(test.grammar)); err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
+
+// The grammar of W3C EBNF, written in W3C EBNF.
+const w3c = `/* Productions are separated by whitespace. */
+Grammar    ::= S? (Production S?)*
+Production ::= (Number S?)? Symbol S? '::=' S? Choice
+Number     ::= '[' [0-9]+ [a-z]? ']'
+Choice     ::= Sequence (S? '|' S? Sequence)*
+Sequence   ::= Difference (S? Difference)*
+Difference ::= Item (S? '-' S? Item)?
+Item       ::= Primary [?*+]?
+Primary    ::= Symbol - (Symbol S? '::=') /* [ WFC: not the start of a production ] */
+             | String | CharCode | Class | '(' S? Choice S? ')'
+Symbol     ::= [A-Za-z_] [A-Za-z0-9_]*
+String     ::= '"' [^"]+ '"' | "'" [^']+ "'"
+CharCode   ::= '#x' [0-9a-fA-F]+
+Class      ::= '[' '^'? (Range | ClassChar)+ ']'
+Range      ::= ClassChar '-' ClassChar
+ClassChar  ::= CharCode | [^#x5D]
+S          ::= (#x20 | #x9 | #xD | #xA | Comment)+
+Comment    ::= '/*' ([^*] | '*'+ [^*/])* '*'+ '/'
+`
+
+// TestCompile_w3c checks whether the compiled grammar of W3C EBNF is able to
+// parse itself.
+func TestCompile_w3c(t *testing.T) {
+	g, err := ebnf.Compile([]byte(w3c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := g.Parse("Grammar", []byte(w3c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.End != len(w3c) {
+		t.Fatalf("parsed %d of %d bytes", n.End, len(w3c))
+	}
+	var productions int
+	for _, c := range n.Children() {
+		if c.TypeString() == "Production" {
+			productions++
+		}
+	}
+	if expected := len(g.Rules()); productions != expected {
+		t.Errorf("expected %d productions, got %d", expected, productions)
+	}
+}
@@ -0,0 +1,223 @@
+// Package ebnf imports grammars written in the EBNF notation of the W3C, as used
+// in the XML specification (section 6, "Notation").
+package ebnf
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// Node Types
+const (
+	Unknown = iota
+
+	// W3C EBNF (https://www.w3.org/TR/xml/#sec-notation)
+	GrammarType      // 001
+	ProductionType   // 002
+	SymbolType       // 003
+	ChoiceType       // 004
+	SequenceType     // 005
+	DifferenceType   // 006
+	ItemType         // 007
+	QuantifierType   // 008
+	StringType       // 009
+	CharCodeType     // 010
+	ClassType        // 011
+	NegatedClassType // 012
+	RangeType        // 013
+	CharType         // 014
+)
+
+var NodeTypes = []string{
+	"UNKNOWN",
+
+	// W3C EBNF (https://www.w3.org/TR/xml/#sec-notation)
+	"Grammar",
+	"Production",
+	"Symbol",
+	"Choice",
+	"Sequence",
+	"Difference",
+	"Item",
+	"Quantifier",
+	"String",
+	"CharCode",
+	"Class",
+	"NegatedClass",
+	"Range",
+	"Char",
+}
+
+// Parse parses the given EBNF grammar. Productions can be numbered (e.g. [1]),
+// comments (/* ... */) and constraints (e.g. [ WFC: ... ]) are ignored.
+func Parse(data []byte) (*ast.Node, error) {
+	return ast.Parse(data, Grammar)
+}
+
+// Grammar ::= S? (Production S?)*
+func Grammar(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        GrammarType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{spacing, op.MinZero(op.And{Production, spacing}), parser.EOD},
+	})
+}
+
+// Production ::= Number? Symbol '::=' Choice
+func Production(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ProductionType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			op.Optional(op.And{number, spacing}),
+			Symbol, spacing, "::=", spacing, Choice,
+		},
+	})
+}
+
+// Symbol ::= [A-Za-z_] [A-Za-z0-9_]*
+func Symbol(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        SymbolType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{symbolStart, op.MinZero(op.Or{symbolStart, digit})},
+	})
+}
+
+// Choice ::= Sequence ('|' Sequence)*
+func Choice(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ChoiceType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Sequence, op.MinZero(op.And{spacing, '|', spacing, Sequence})},
+	})
+}
+
+// Sequence ::= Difference (S Difference)*
+func Sequence(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        SequenceType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Difference, op.MinZero(op.And{spacing, Difference})},
+	})
+}
+
+// Difference ::= Item ('-' Item)?
+func Difference(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        DifferenceType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Item, op.Optional(op.And{spacing, '-', spacing, Item})},
+	})
+}
+
+// Item ::= Primary ('?' | '*' | '+')?
+func Item(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ItemType,
+		TypeStrings: NodeTypes,
+		Value: op.And{primary, op.Optional(ast.Capture{
+			Type:        QuantifierType,
+			TypeStrings: NodeTypes,
+			Value:       op.Or{'?', '*', '+'},
+		})},
+	})
+}
+
+// Primary ::= Symbol | String | CharCode | Class | '(' Choice ')'
+//
+// A symbol followed by '::=' (and its number) starts the next production.
+func primary(p *ast.Parser) (*ast.Node, error) {
+	next := op.And{op.Optional(op.And{number, spacing}), Symbol, spacing, "::="}
+	return p.Expect(op.And{
+		op.Not{Value: next},
+		op.Or{
+			Symbol,
+			String,
+			CharCode,
+			Class,
+			op.And{'(', spacing, Choice, spacing, ')'},
+		},
+	})
+}
+
+// String ::= '"' [^"]* '"' | "'" [^']* "'"
+func String(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.Or{
+		op.And{'"', ast.Capture{
+			Type:        StringType,
+			TypeStrings: NodeTypes,
+			Value:       op.MinOne(op.And{op.Not{Value: '"'}, any}),
+		}, '"'},
+		op.And{'\'', ast.Capture{
+			Type:        StringType,
+			TypeStrings: NodeTypes,
+			Value:       op.MinOne(op.And{op.Not{Value: '\''}, any}),
+		}, '\''},
+	})
+}
+
+// CharCode ::= '#x' [0-9a-fA-F]+
+func CharCode(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        CharCodeType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{"#x", op.MinOne(hexdig)},
+	})
+}
+
+// Class ::= '[' '^'? (Range | Char | CharCode)+ ']'
+func Class(p *ast.Parser) (*ast.Node, error) {
+	chars := op.MinOne(op.Or{
+		ast.Capture{
+			Type:        RangeType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{classChar, '-', classChar},
+		},
+		classChar,
+	})
+	return p.Expect(op.Or{
+		ast.Capture{
+			Type:        NegatedClassType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{"[^", chars, ']'},
+		},
+		ast.Capture{
+			Type:        ClassType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{'[', chars, ']'},
+		},
+	})
+}
+
+// classChar ::= CharCode | [^#x5D]
+func classChar(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.Or{
+		CharCode,
+		ast.Capture{
+			Type:        CharType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{op.Not{Value: ']'}, any},
+		},
+	})
+}
+
+var (
+	digit       = parser.CheckRuneRange('0', '9')
+	hexdig      = op.Or{digit, parser.CheckRuneRange('a', 'f'), parser.CheckRuneRange('A', 'F')}
+	symbolStart = op.Or{parser.CheckRuneRange('a', 'z'), parser.CheckRuneRange('A', 'Z'), '_'}
+	any         = parser.AnyRune{}
+	// number ::= '[' [0-9]+ [a-z]? ']'
+	number = op.And{'[', op.MinOne(digit), op.Optional(parser.CheckRuneRange('a', 'z')), ']'}
+	// constraint ::= '[' S? ('WFC' | 'VC') ':' [^#x5D]* ']'
+	constraint = op.And{
+		'[', op.MinZero(op.Or{' ', '\t'}),
+		op.Or{parser.CheckStringCI("wfc"), parser.CheckStringCI("vc")}, ':',
+		op.MinZero(op.And{op.Not{Value: ']'}, any}), ']',
+	}
+	// comment ::= '/*' (any - '*/')* '*/'
+	comment = op.And{"/*", op.MinZero(op.And{op.Not{Value: "*/"}, any}), "*/"}
+	// S ::= (#x20 | #x9 | #xD | #xA | comment | constraint)*
+	spacing = op.MinZero(op.Or{' ', '\t', '\r', '\n', comment, constraint})
+)
@@ -5,6 +5,7 @@ import (
 	"github.com/di-wu/parser/op"
 	"reflect"
 	"strings"
+	"unicode"
 )
 
 // InitError is an error that occurs on instantiating new structures.
@@ -51,6 +52,10 @@ type ExpectedParseError struct {
 	String string
 	// The position of the conflicting value.
 	Conflict Cursor
+	// Label of the op.Expect that failed, if any. The parser does not
+	// backtrack from labeled errors, they are returned as is by all the
+	// enclosing values.
+	Label string
 }
 
 func Stringer(i interface{}) string {
@@ -86,6 +91,37 @@ func Stringer(i interface{}) string {
 			xor[i] = Stringer(v)
 		}
 		return fmt.Sprintf("xor[%s]", strings.Join(xor, " "))
+	case op.Named:
+		return fmt.Sprintf("(?<%s>%s)", v.Name, Stringer(v.Value))
+	case op.BackRef:
+		return fmt.Sprintf("\\k<%s>", v.Name)
+	case op.Quoted:
+		quotes := v.Quotes
+		if len(quotes) == 0 {
+			quotes = []string{`"`}
+		}
+		or := make([]string, len(quotes))
+		for i, q := range quotes {
+			or[i] = q + "..." + q
+		}
+		return strings.Join(or, " ")
+	case op.Cut:
+		return "~"
+	case op.Expect:
+		return Stringer(v.Value)
+	case op.Token:
+		return Stringer(v.Value)
+	case op.Indent:
+		return "INDENT"
+	case op.Dedent:
+		return "DEDENT"
+	case op.SameIndent:
+		return "SAMEINDENT"
+	case *unicode.RangeTable:
+		if name, ok := tableName(v); ok {
+			return fmt.Sprintf("\\p{%s}", name)
+		}
+		return "unicode.RangeTable"
 	case op.Range:
 		if v.Max == -1 {
 			switch v.Min {
@@ -109,12 +145,50 @@ func (e *ExpectedParseError) Error() string {
 		got = fmt.Sprintf("%q", e.String)
 	}
 
+	var label string
+	if e.Label != "" {
+		label = e.Label + ": "
+	}
 	return fmt.Sprintf(
-		"parse conflict [%02d:%03d]: expected %T %s but got %s",
-		e.Conflict.row, e.Conflict.column, e.Expected, Stringer(e.Expected), got,
+		"parse conflict [%02d:%03d]: %sexpected %T %s but got %s",
+		e.Conflict.row, e.Conflict.column, label, e.Expected, Stringer(e.Expected), got,
 	)
 }
 
+// CutError indicates that a value failed after a cut (op.Cut). It propagates up
+// to the op.Or (or op.XOr) that contains the cut, which does not try its other
+// alternatives but returns the wrapped error as a regular failure. Predicates
+// (op.Not and op.Ensure) also treat it as a regular failure of their value. It
+// is never returned by the outermost Expect.
+type CutError struct {
+	// The error of the value that failed after the cut.
+	Err error
+}
+
+func (e *CutError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CutError) Unwrap() error {
+	return e.Err
+}
+
+// Committed returns whether the parser does not backtrack from the given error
+// into other alternatives: a failure after a cut (CutError) or a labeled error
+// (see op.Expect).
+func Committed(err error) bool {
+	if _, ok := err.(*CutError); ok {
+		return true
+	}
+	return isLabeled(err)
+}
+
+// isLabeled returns whether the error is a labeled ExpectedParseError.
+func isLabeled(err error) bool {
+	e, ok := err.(*ExpectedParseError)
+	return ok && e.Label != ""
+}
+
 // UnsupportedType indicates the type of the value is unsupported.
 type UnsupportedType struct {
 	Value interface{}
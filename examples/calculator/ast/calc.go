@@ -1,6 +1,7 @@
 package calc_ast
 
 import (
+	"fmt"
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/ast"
 	"github.com/di-wu/parser/op"
@@ -20,37 +21,30 @@ func Evaluate(s string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return EvaluateNode(n), nil
+	return NodeValue(n)
 }
 
+// EvaluateNode returns the value of the given node, or 0 if it can not be
+// evaluated. See NodeValue.
 func EvaluateNode(n *ast.Node) int {
-	var value int
+	value, _ := NodeValue(n)
+	return value
+}
+
+// NodeValue returns the value of the given node. The values get calculated by
+// the actions of the captures while parsing, nodes without a result (e.g.
+// constructed by hand) are evaluated by walking the tree.
+func NodeValue(n *ast.Node) (int, error) {
+	if value, ok := n.Result.(int); ok {
+		return value, nil
+	}
 	switch n.Type {
 	case 1, 2:
-		var sign rune
-		for _, c := range n.Children() {
-			if c.Type == 3 || c.Type == 4 {
-				sign = []rune(c.Value)[0]
-			} else {
-				switch sign {
-				case '+':
-					value += EvaluateNode(c)
-				case '-':
-					value -= EvaluateNode(c)
-				case '*':
-					value *= EvaluateNode(c)
-				case '/':
-					value /= EvaluateNode(c)
-				default:
-					value = EvaluateNode(c)
-				}
-			}
-		}
+		return calculate(n.Children())
 	case 5:
-		i, _ := strconv.Atoi(n.Value)
-		return i
+		return strconv.Atoi(n.Value)
 	}
-	return value
+	return 0, fmt.Errorf("can not evaluate %s node", n.TypeString())
 }
 
 func AddSub(p *ast.Parser) (*ast.Node, error) {
@@ -85,9 +79,51 @@ func operator(p *ast.Parser, typ int, f func(p *ast.Parser) (*ast.Node, error),
 				Space, or, Space, f,
 			}),
 		},
+		Action: evaluate,
 	})
 }
 
+// evaluate is the action of the expressions, see calculate.
+func evaluate(_ string, children []*ast.Node) (interface{}, error) {
+	value, err := calculate(children)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// calculate calculates the value of an expression. The children are the
+// operands, separated by the operators.
+func calculate(children []*ast.Node) (int, error) {
+	if len(children) == 0 {
+		return 0, fmt.Errorf("empty expression")
+	}
+	value, err := NodeValue(children[0])
+	if err != nil {
+		return 0, err
+	}
+	for i := 1; i+1 < len(children); i += 2 {
+		operand, err := NodeValue(children[i+1])
+		if err != nil {
+			return 0, err
+		}
+		switch children[i].Value {
+		case "+":
+			value += operand
+		case "-":
+			value -= operand
+		case "*":
+			value *= operand
+		case "/":
+			if operand == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			value /= operand
+		}
+	}
+	return value, nil
+}
+
 func Factor(p *ast.Parser) (*ast.Node, error) {
 	return p.Expect(op.Or{
 		Integer,
@@ -104,6 +140,9 @@ func Integer(p *ast.Parser) (*ast.Node, error) {
 				return '0' <= r && r <= '9'
 			}),
 		),
+		Action: func(match string, _ []*ast.Node) (interface{}, error) {
+			return strconv.Atoi(match)
+		},
 	})
 }
 
@@ -26,12 +26,29 @@ func ExampleEvaluate() {
 	fmt.Println(calc.Evaluate("1 + 1 * 1 + 1"))
 	fmt.Println(calc.Evaluate("1 + 1 * (1 + 1)"))
 	fmt.Println(calc.Evaluate("(1 + 1) * (1 + 1)"))
+	fmt.Println(calc.Evaluate("1 / (1 - 1)"))
 	// Output:
 	// 2 <nil>
 	// 2 <nil>
 	// 3 <nil>
 	// 3 <nil>
 	// 4 <nil>
+	// 0 action MulDivExpr: "1 / (1 - 1)": division by zero
+}
+
+func ExampleNodeValue() {
+	types := []string{"UNKNOWN", "AddSubExpr", "MulDivExpr", "AddSub", "MulDiv", "Integer"}
+	n := &ast.Node{Type: 2, TypeStrings: types}
+	n.SetLast(&ast.Node{Type: 5, TypeStrings: types, Value: "6"})
+	n.SetLast(&ast.Node{Type: 4, TypeStrings: types, Value: "*"})
+	n.SetLast(&ast.Node{Type: 5, TypeStrings: types, Value: "7"})
+	fmt.Println(calc.NodeValue(n))
+	fmt.Println(calc.NodeValue(n.Children()[1]))
+	fmt.Println(calc.EvaluateNode(n))
+	// Output:
+	// 42 <nil>
+	// 0 can not evaluate MulDiv node
+	// 42
 }
 
 func ExampleInteger() {
@@ -0,0 +1,453 @@
+// Package grammar represents grammars as a table of named rules. The rules are
+// expressed with the values supported by the ast parser, references between
+// rules are resolved at parse time. This allows grammars to be loaded (e.g.
+// from a PEGN file) and used without generating any code.
+package grammar
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"sort"
+)
+
+// Rule is a named rule of a grammar.
+type Rule struct {
+	// Name of the rule.
+	Name string
+	// Capture indicates whether the rule produces a node, which has the name
+	// of the rule as type string. e.g. PEGN definitions with the `<--`
+	// operator.
+	Capture bool
+	// Value is the expression of the rule. References to other rules are
+	// represented by Ref values.
+	Value interface{}
+}
+
+// Ref is a reference to a rule of the grammar.
+type Ref struct {
+	Name string
+}
+
+// Original is a reference to a rule that got replaced by an override. It is
+// used to extend the replaced rule, see Override.
+type Original struct {
+	Rule *Rule
+}
+
+// Grammar is an ordered collection of rules.
+type Grammar struct {
+	rules []*Rule
+	index map[string]int
+
+	// table contains the resolved values of all the rules, it is shared by
+	// all the ast.LoopUp values of the grammar.
+	table map[string]interface{}
+	// typeStrings contains the type strings of the nodes, the type of a node
+	// is the (one based) index of its rule.
+	typeStrings []string
+	dirty       bool
+}
+
+// New creates a new empty Grammar.
+func New() *Grammar {
+	return &Grammar{
+		index: make(map[string]int),
+		table: make(map[string]interface{}),
+	}
+}
+
+// Add adds a rule to the grammar. Returns an error if a rule with the same
+// name already exists.
+func (g *Grammar) Add(r *Rule) error {
+	if _, ok := g.index[r.Name]; ok {
+		return fmt.Errorf("grammar: rule %s is already defined", r.Name)
+	}
+	g.index[r.Name] = len(g.rules)
+	g.rules = append(g.rules, r)
+	g.dirty = true
+	return nil
+}
+
+// Set adds the rule to the grammar, or replaces the rule with the same name.
+func (g *Grammar) Set(r *Rule) {
+	if i, ok := g.index[r.Name]; ok {
+		g.rules[i] = r
+		g.dirty = true
+		return
+	}
+	_ = g.Add(r)
+}
+
+// Override replaces the rule with the same name, e.g. a rule of an imported
+// grammar. All references to the rule refer to the new rule, except for the
+// ones within the new rule itself: those refer to the replaced rule. This way a
+// rule can be extended, e.g. `Value <- Comment* Value`. Returns an error if the
+// rule does not exist.
+func (g *Grammar) Override(r *Rule) error {
+	i, ok := g.index[r.Name]
+	if !ok {
+		return fmt.Errorf("grammar: rule %s is not defined", r.Name)
+	}
+	original := Original{Rule: g.rules[i]}
+	g.rules[i] = &Rule{
+		Name:    r.Name,
+		Capture: r.Capture,
+		Value: Map(r.Value, func(i interface{}) interface{} {
+			if ref, ok := i.(Ref); ok && ref.Name == r.Name {
+				return original
+			}
+			return i
+		}),
+	}
+	g.dirty = true
+	return nil
+}
+
+// Import adds copies of all the rules of the other grammar. The names of the
+// rules, and the references to them, are prefixed with the namespace (e.g.
+// json.Value). An empty namespace merges the rules as they are. Returns an
+// error if one of the rules is already defined, in which case no rules are
+// added.
+//
+// The imported rules can be replaced with Override.
+func (g *Grammar) Import(namespace string, other *Grammar) error {
+	prefix := namespace
+	if prefix != "" {
+		prefix += "."
+	}
+	for _, r := range other.rules {
+		if _, ok := g.index[prefix+r.Name]; ok {
+			return fmt.Errorf("grammar: rule %s is already defined", prefix+r.Name)
+		}
+	}
+	for _, r := range other.rules {
+		_ = g.Add(rename(r, prefix))
+	}
+	return nil
+}
+
+// rename returns a copy of the rule of which the name and all the references
+// are prefixed with the given prefix.
+func rename(r *Rule, prefix string) *Rule {
+	return &Rule{
+		Name:    prefix + r.Name,
+		Capture: r.Capture,
+		Value: Map(r.Value, func(i interface{}) interface{} {
+			switch v := i.(type) {
+			case Ref:
+				return Ref{Name: prefix + v.Name}
+			case Original:
+				return Original{Rule: &Rule{
+					Name:    prefix + v.Rule.Name,
+					Capture: v.Rule.Capture,
+					Value:   v.Rule.Value,
+				}}
+			}
+			return i
+		}),
+	}
+}
+
+// Rule returns the rule with the given name, nil if it does not exist.
+func (g *Grammar) Rule(name string) *Rule {
+	if i, ok := g.index[name]; ok {
+		return g.rules[i]
+	}
+	return nil
+}
+
+// Rules returns all the rules in the order they were added.
+func (g *Grammar) Rules() []*Rule {
+	return append([]*Rule(nil), g.rules...)
+}
+
+// TypeStrings returns the type strings of the nodes produced by the grammar.
+// The first element is "UNKNOWN", followed by the names of all the rules.
+func (g *Grammar) TypeStrings() []string {
+	g.resolve()
+	return g.typeStrings
+}
+
+// Type returns the type of the nodes produced by the rule with the given name,
+// -1 if the rule does not exist.
+func (g *Grammar) Type(name string) int {
+	if i, ok := g.index[name]; ok {
+		return i + 1
+	}
+	return -1
+}
+
+// Check checks whether all the references within the grammar refer to existing
+// rules. The names of the missing rules are sorted. It also checks whether the
+// types of the nodes are unique, captures within the rules (e.g. of an imported
+// Go grammar) can not use the type of another rule or capture.
+func (g *Grammar) Check() error {
+	missing := make(map[string]bool)
+	for _, r := range g.rules {
+		Walk(r.Value, func(i interface{}) {
+			if ref, ok := i.(Ref); ok {
+				if _, ok := g.index[ref.Name]; !ok {
+					missing[ref.Name] = true
+				}
+			}
+		})
+	}
+	if len(missing) != 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return &UndefinedError{Names: names}
+	}
+
+	types := make(map[int]string)
+	for i, r := range g.rules {
+		if r.Capture {
+			types[i+1] = r.Name
+		}
+	}
+	var conflict *TypeError
+	for _, r := range g.rules {
+		Walk(r.Value, func(i interface{}) {
+			c, ok := i.(ast.Capture)
+			if !ok || conflict != nil {
+				return
+			}
+			name, ok := types[c.Type]
+			if !ok {
+				types[c.Type] = c.String()
+				return
+			}
+			if name != c.String() {
+				conflict = &TypeError{Type: c.Type, Names: [2]string{name, c.String()}}
+			}
+		})
+		if conflict != nil {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// UndefinedError is returned if a grammar refers to rules that do not exist.
+type UndefinedError struct {
+	Names []string
+}
+
+func (e *UndefinedError) Error() string {
+	return fmt.Sprintf("grammar: undefined rules: %v", e.Names)
+}
+
+// TypeError is returned if the nodes of different rules or captures have the
+// same type.
+type TypeError struct {
+	Type  int
+	Names [2]string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("grammar: type %d is used by both %s and %s", e.Type, e.Names[0], e.Names[1])
+}
+
+// Value returns the value that matches the rule with the given name. The value
+// can be passed to the Expect method of an ast.Parser.
+func (g *Grammar) Value(name string) (interface{}, error) {
+	if _, ok := g.index[name]; !ok {
+		return nil, fmt.Errorf("grammar: rule %s is not defined", name)
+	}
+	g.resolve()
+	return ast.LoopUp{
+		Key:   name,
+		Table: &g.table,
+	}, nil
+}
+
+// ParseNode returns a function that parses the rule with the given name.
+func (g *Grammar) ParseNode(name string) (ast.ParseNode, error) {
+	v, err := g.Value(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(p *ast.Parser) (*ast.Node, error) {
+		// Rules might have changed since the creation of the function.
+		g.resolve()
+		return p.Expect(v)
+	}, nil
+}
+
+// Parse parses the given data, starting with the rule with the given name.
+func (g *Grammar) Parse(name string, data []byte) (*ast.Node, error) {
+	node, err := g.ParseNode(name)
+	if err != nil {
+		return nil, err
+	}
+	return ast.Parse(data, node)
+}
+
+// resolve (re)builds the table of resolved rules, if the grammar changed.
+func (g *Grammar) resolve() {
+	if !g.dirty && g.typeStrings != nil {
+		return
+	}
+	g.typeStrings = []string{"UNKNOWN"}
+	for _, r := range g.rules {
+		g.typeStrings = append(g.typeStrings, r.Name)
+	}
+	for k := range g.table {
+		delete(g.table, k)
+	}
+	for i, r := range g.rules {
+		v := g.value(r.Value)
+		if r.Capture {
+			v = ast.Capture{
+				Type:        i + 1,
+				TypeStrings: g.typeStrings,
+				Value:       v,
+			}
+		}
+		g.table[r.Name] = v
+	}
+	g.dirty = false
+}
+
+// value replaces all the references within the given value by ast.LoopUp values.
+func (g *Grammar) value(i interface{}) interface{} {
+	return Map(i, func(i interface{}) interface{} {
+		switch v := i.(type) {
+		case Ref:
+			return ast.LoopUp{
+				Key:   v.Name,
+				Table: &g.table,
+			}
+		case Original:
+			if v.Rule.Capture {
+				return ast.Capture{
+					Type:        g.Type(v.Rule.Name),
+					TypeStrings: g.typeStrings,
+					Value:       v.Rule.Value,
+				}
+			}
+			return v.Rule.Value
+		}
+		return i
+	})
+}
+
+// Walk calls the given function for the value and all the values it consists
+// of, parents before their children.
+func Walk(i interface{}, f func(i interface{})) {
+	f(i)
+	switch v := i.(type) {
+	case op.And:
+		for _, i := range v {
+			Walk(i, f)
+		}
+	case op.Or:
+		for _, i := range v {
+			Walk(i, f)
+		}
+	case op.XOr:
+		for _, i := range v {
+			Walk(i, f)
+		}
+	case op.Not:
+		Walk(v.Value, f)
+	case op.Ensure:
+		Walk(v.Value, f)
+	case op.Range:
+		Walk(v.Value, f)
+	case op.Named:
+		Walk(v.Value, f)
+	case op.Expect:
+		Walk(v.Value, f)
+	case op.Token:
+		Walk(v.Value, f)
+	case ast.Capture:
+		Walk(v.Value, f)
+	case Original:
+		Walk(v.Rule.Value, f)
+	}
+}
+
+// Map returns a copy of the given value in which every value it consists of is
+// replaced by the result of the given function. Children are mapped before
+// their parents.
+func Map(i interface{}, f func(i interface{}) interface{}) interface{} {
+	switch v := i.(type) {
+	case op.And:
+		return f(op.And(mapAll(v, f)))
+	case op.Or:
+		return f(op.Or(mapAll(v, f)))
+	case op.XOr:
+		return f(op.XOr(mapAll(v, f)))
+	case op.Not:
+		return f(op.Not{Value: Map(v.Value, f)})
+	case op.Ensure:
+		return f(op.Ensure{Value: Map(v.Value, f)})
+	case op.Range:
+		v.Value = Map(v.Value, f)
+		return f(v)
+	case op.Named:
+		v.Value = Map(v.Value, f)
+		return f(v)
+	case op.Expect:
+		v.Value = Map(v.Value, f)
+		return f(v)
+	case op.Token:
+		return f(op.Token{Value: Map(v.Value, f)})
+	case ast.Capture:
+		v.Value = Map(v.Value, f)
+		return f(v)
+	case Original:
+		return f(Original{Rule: &Rule{
+			Name:    v.Rule.Name,
+			Capture: v.Rule.Capture,
+			Value:   Map(v.Rule.Value, f),
+		}})
+	default:
+		return f(i)
+	}
+}
+
+func mapAll(values []interface{}, f func(i interface{}) interface{}) []interface{} {
+	mapped := make([]interface{}, len(values))
+	for i, v := range values {
+		mapped[i] = Map(v, f)
+	}
+	return mapped
+}
+
+// ImportError is returned if a grammar written in another notation (e.g. ABNF)
+// could not be imported.
+type ImportError struct {
+	// Notation of the imported grammar, e.g. "abnf".
+	Notation string
+	// Line and Column of the conflicting value, zero based.
+	Line, Column int
+	Message      string
+}
+
+// NewImportError returns an error at the given offset within the data.
+func NewImportError(notation string, data []byte, offset int, format string, a ...interface{}) *ImportError {
+	var line, column int
+	for _, r := range string(data[:offset]) {
+		if r == '\n' {
+			line++
+			column = 0
+			continue
+		}
+		column++
+	}
+	return &ImportError{
+		Notation: notation,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%s [%02d:%03d]: %s", e.Notation, e.Line, e.Column, e.Message)
+}
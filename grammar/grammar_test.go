@@ -0,0 +1,113 @@
+package grammar_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleGrammar() {
+	g := grammar.New()
+	_ = g.Add(&grammar.Rule{
+		Name:    "List",
+		Capture: true,
+		Value: op.And{
+			'[',
+			op.Optional(op.And{
+				grammar.Ref{Name: "Value"},
+				op.MinZero(op.And{',', grammar.Ref{Name: "Value"}}),
+			}),
+			']',
+		},
+	})
+	_ = g.Add(&grammar.Rule{
+		Name: "Value",
+		Value: op.Or{
+			grammar.Ref{Name: "List"},
+			grammar.Ref{Name: "Digit"},
+		},
+	})
+	_ = g.Add(&grammar.Rule{
+		Name:    "Digit",
+		Capture: true,
+		Value:   parser.CheckRuneRange('0', '9'),
+	})
+	fmt.Println(g.TypeStrings())
+	fmt.Println(g.Parse("List", []byte("[1,[2,3],[]]")))
+	// Output:
+	// [UNKNOWN List Value Digit]
+	// ["List",[["Digit","1"],["List",[["Digit","2"],["Digit","3"]]],["List","[]"]]] <nil>
+}
+
+func ExampleGrammar_Check() {
+	g := grammar.New()
+	_ = g.Add(&grammar.Rule{
+		Name:  "Pair",
+		Value: op.And{grammar.Ref{Name: "Key"}, '=', grammar.Ref{Name: "Value"}},
+	})
+	fmt.Println(g.Check())
+	_ = g.Add(&grammar.Rule{
+		Name:  "Key",
+		Value: op.MinOne(parser.CheckRuneRange('a', 'z')),
+	})
+	fmt.Println(g.Check())
+	// Output:
+	// grammar: undefined rules: [Key Value]
+	// grammar: undefined rules: [Value]
+}
+
+func ExampleGrammar_Set() {
+	g := grammar.New()
+	_ = g.Add(&grammar.Rule{Name: "Bit", Capture: true, Value: '0'})
+	parse, _ := g.ParseNode("Bit")
+
+	fmt.Println(g.Parse("Bit", []byte("1")))
+	g.Set(&grammar.Rule{Name: "Bit", Capture: true, Value: op.Or{'0', '1'}})
+	fmt.Println(ast.Parse([]byte("1"), parse))
+	// Output:
+	// <nil> parse conflict [00:000]: expected int32 '0' but got '1'
+	// ["Bit","1"] <nil>
+}
+
+func ExampleGrammar_Import() {
+	csv := grammar.New()
+	_ = csv.Add(&grammar.Rule{
+		Name:    "Record",
+		Capture: true,
+		Value:   op.And{grammar.Ref{Name: "Field"}, op.MinZero(op.And{',', grammar.Ref{Name: "Field"}})},
+	})
+	_ = csv.Add(&grammar.Rule{
+		Name:    "Field",
+		Capture: true,
+		Value:   op.MinZero(parser.CheckRuneRange('a', 'z')),
+	})
+
+	g := grammar.New()
+	_ = g.Import("csv", csv)
+	// Allow fields to be quoted.
+	_ = g.Override(&grammar.Rule{
+		Name:  "csv.Field",
+		Value: op.Or{op.And{'"', grammar.Ref{Name: "csv.Field"}, '"'}, grammar.Ref{Name: "csv.Field"}},
+	})
+	fmt.Println(g.TypeStrings())
+	fmt.Println(g.Parse("csv.Record", []byte(`a,"b",c`)))
+	// Output:
+	// [UNKNOWN csv.Record csv.Field]
+	// ["csv.Record",[["csv.Field","a"],["csv.Field","b"],["csv.Field","c"]]] <nil>
+}
+
+func ExampleGrammar_Check_types() {
+	g := grammar.New()
+	_ = g.Add(&grammar.Rule{Name: "Number", Capture: true, Value: op.MinOne(parser.CheckRuneRange('0', '9'))})
+	// A capture of another (Go) grammar, of which the type conflicts with the
+	// type of the first rule.
+	_ = g.Add(&grammar.Rule{Name: "Value", Value: op.Or{
+		grammar.Ref{Name: "Number"},
+		ast.Capture{Type: 1, TypeStrings: []string{"UNKNOWN", "String"}, Value: op.And{'"', op.MinZero(parser.CheckRuneRange('a', 'z')), '"'}},
+	}})
+	fmt.Println(g.Check())
+	// Output:
+	// grammar: type 1 is used by both Number and String
+}
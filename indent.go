@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/op"
+)
+
+// indentation is a stack of indentation levels. It is immutable so cursors can
+// share it, this way Jump also restores the indentation levels.
+type indentation struct {
+	width  int
+	parent *indentation
+}
+
+// Indentation returns the width of the current indentation level.
+func (c *Cursor) Indentation() int {
+	return c.indent.level()
+}
+
+// SetTabWidth sets the number of columns a tab advances the indentation to,
+// this is 8 by default. A width less than 1 disallows tabs within indentation.
+func (p *Parser) SetTabWidth(w int) {
+	p.tabWidth = w
+}
+
+// expectIndent checks the indentation of the current line, see op.Indent,
+// op.Dedent and op.SameIndent.
+func (p *Parser) expectIndent(i interface{}) (*Cursor, error) {
+	start := p.Mark()
+	if _, dedent := i.(op.Dedent); p.cursor.column != 0 && !(dedent && p.Done()) {
+		// Indentation is only allowed at the start of a line.
+		return nil, p.ExpectedParseError(i, start, start)
+	}
+	width, last, err := p.measureIndent()
+	if err != nil {
+		p.Jump(start)
+		return nil, err
+	}
+	current := start.indent
+	switch i.(type) {
+	case op.Indent:
+		if width <= current.level() {
+			return nil, p.ExpectedParseError(i, start, p.Mark())
+		}
+		p.cursor.indent = &indentation{
+			width:  width,
+			parent: current,
+		}
+		return last, nil
+	case op.SameIndent:
+		if width != current.level() {
+			return nil, p.ExpectedParseError(i, start, p.Mark())
+		}
+		return last, nil
+	default: // op.Dedent
+		end := p.Mark()
+		p.Jump(start)
+		if end.Rune == EOD {
+			// The end of the data closes all levels.
+			width = 0
+		}
+		if current == nil || current.width <= width {
+			return nil, p.ExpectedParseError(i, start, end)
+		}
+		if current.parent.level() < width {
+			// Does not match any of the outer levels.
+			return nil, &IndentError{
+				Message:  fmt.Sprintf("unindent to %d does not match any outer indentation level", width),
+				Conflict: *end,
+			}
+		}
+		p.cursor.indent = current.parent
+		return nil, nil
+	}
+}
+
+// measureIndent consumes the spaces and tabs at the current position and
+// returns the width of the indentation and a mark to the last consumed rune.
+func (p *Parser) measureIndent() (int, *Cursor, error) {
+	var (
+		width int
+		last  *Cursor
+	)
+	for {
+		switch p.cursor.Rune {
+		case ' ':
+			width++
+		case '\t':
+			if p.tabWidth < 1 {
+				return 0, nil, &IndentError{
+					Message:  "tab in indentation",
+					Conflict: *p.Mark(),
+				}
+			}
+			width += p.tabWidth - width%p.tabWidth
+		default:
+			return width, last, nil
+		}
+		last = p.Mark()
+		p.Next()
+	}
+}
+
+// level returns the width of the indentation level, 0 if there is none.
+func (i *indentation) level() int {
+	if i == nil {
+		return 0
+	}
+	return i.width
+}
+
+// IndentError indicates that the indentation of a line is inconsistent.
+type IndentError struct {
+	Message string
+	// The position of the conflicting indentation.
+	Conflict Cursor
+}
+
+func (e *IndentError) Error() string {
+	return fmt.Sprintf(
+		"indentation error [%02d:%03d]: %s",
+		e.Conflict.row, e.Conflict.column, e.Message,
+	)
+}
@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Client is a minimal language client. It is mainly intended to test language
+// servers in process, see Connect.
+type Client struct {
+	conn *Conn
+
+	mu      sync.Mutex
+	id      int
+	pending map[string]chan *Message
+	err     error
+
+	// notifications is an (unbounded) queue of the received notifications.
+	notifications []*Message
+	received      *sync.Cond
+	done          chan struct{}
+}
+
+// NewClient creates a new Client that reads the messages of the server from
+// the reader and writes its own messages to the writer.
+func NewClient(r io.Reader, w io.Writer) *Client {
+	c := &Client{
+		conn:    NewConn(r, w),
+		pending: make(map[string]chan *Message),
+		done:    make(chan struct{}),
+	}
+	c.received = sync.NewCond(&c.mu)
+	go c.read()
+	return c
+}
+
+// Connect starts the given server and returns a client that is connected to
+// it. Closing the client stops the server.
+func Connect(s *Server) *Client {
+	var (
+		serverReader, clientWriter = io.Pipe()
+		clientReader, serverWriter = io.Pipe()
+	)
+	go func() {
+		err := s.Serve(serverReader, serverWriter)
+		_ = serverReader.CloseWithError(err)
+		_ = serverWriter.CloseWithError(err)
+	}()
+	return NewClient(clientReader, clientWriter)
+}
+
+// read dispatches the messages of the server until the connection is closed.
+func (c *Client) read() {
+	defer close(c.done)
+	for {
+		m, err := c.conn.Read()
+		if err != nil {
+			c.mu.Lock()
+			c.err = err
+			for id, ch := range c.pending {
+				close(ch)
+				delete(c.pending, id)
+			}
+			c.received.Broadcast()
+			c.mu.Unlock()
+			return
+		}
+		if m.IsRequest() {
+			c.mu.Lock()
+			c.notifications = append(c.notifications, m)
+			c.received.Signal()
+			c.mu.Unlock()
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[string(m.ID)]
+		delete(c.pending, string(m.ID))
+		c.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	}
+}
+
+// Call sends a request to the server and waits for its response. The result
+// gets decoded into the given value, unless it is nil.
+func (c *Client) Call(method string, params, result interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.id++
+	id := json.RawMessage(strconv.Itoa(c.id))
+	ch := make(chan *Message, 1)
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+
+	if err := c.conn.Write(&Message{
+		ID:     id,
+		Method: method,
+		Params: raw,
+	}); err != nil {
+		return err
+	}
+	m, ok := <-ch
+	if !ok {
+		return c.err
+	}
+	if m.Error != nil {
+		return m.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(m.Result, result)
+}
+
+// Notify sends a notification to the server.
+func (c *Client) Notify(method string, params interface{}) error {
+	return c.conn.Notify(method, params)
+}
+
+// Notification waits for the next notification (or request) of the server.
+// The parameters get decoded into the given value, unless it is nil. It
+// returns the method of the notification.
+func (c *Client) Notification(params interface{}) (string, error) {
+	c.mu.Lock()
+	for len(c.notifications) == 0 && c.err == nil {
+		c.received.Wait()
+	}
+	if len(c.notifications) == 0 {
+		defer c.mu.Unlock()
+		return "", c.err
+	}
+	m := c.notifications[0]
+	c.notifications = c.notifications[1:]
+	c.mu.Unlock()
+
+	if params == nil {
+		return m.Method, nil
+	}
+	return m.Method, json.Unmarshal(m.Params, params)
+}
+
+// Close closes the connection to the server, if the writer of the client is
+// an io.Closer. It waits until the server closed its side of the connection.
+func (c *Client) Close() error {
+	closer, ok := c.conn.w.(io.Closer)
+	if !ok {
+		return nil
+	}
+	err := closer.Close()
+	<-c.done
+	return err
+}
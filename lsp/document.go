@@ -0,0 +1,107 @@
+package lsp
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Document is a text document that is opened by the client.
+type Document struct {
+	URI     string
+	Version int
+	Text    string
+
+	// lines contains the offsets of the start of every line.
+	lines []int
+}
+
+// NewDocument creates a new Document.
+func NewDocument(uri string, version int, text string) *Document {
+	lines := []int{0}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			lines = append(lines, i+1)
+		case '\n':
+			lines = append(lines, i+1)
+		}
+	}
+	return &Document{
+		URI:     uri,
+		Version: version,
+		Text:    text,
+		lines:   lines,
+	}
+}
+
+// Offset converts the given position to an offset (in bytes) within the text.
+// Positions outside of the text get clamped.
+func (d *Document) Offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if len(d.lines) <= pos.Line {
+		return len(d.Text)
+	}
+	offset, end := d.lines[pos.Line], len(d.Text)
+	if pos.Line+1 < len(d.lines) {
+		end = d.lines[pos.Line+1]
+	}
+	for n := 0; n < pos.Character && offset < end; {
+		r, size := utf8.DecodeRuneInString(d.Text[offset:])
+		if r == '\n' || r == '\r' {
+			break
+		}
+		n += utf16Len(r)
+		offset += size
+	}
+	return offset
+}
+
+// Position converts the given offset (in bytes) to a position.
+func (d *Document) Position(offset int) Position {
+	if offset < 0 {
+		offset = 0
+	}
+	if len(d.Text) < offset {
+		offset = len(d.Text)
+	}
+	line := sort.Search(len(d.lines), func(i int) bool {
+		return offset < d.lines[i]
+	}) - 1
+	var character int
+	for _, r := range d.Text[d.lines[line]:offset] {
+		character += utf16Len(r)
+	}
+	return Position{
+		Line:      line,
+		Character: character,
+	}
+}
+
+// Range converts the given offsets (in bytes) to a range.
+func (d *Document) Range(start, end int) Range {
+	return Range{
+		Start: d.Position(start),
+		End:   d.Position(end),
+	}
+}
+
+// Location returns the location of the given offsets (in bytes).
+func (d *Document) Location(start, end int) Location {
+	return Location{
+		URI:   d.URI,
+		Range: d.Range(start, end),
+	}
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode the rune.
+func utf16Len(r rune) int {
+	if 0x10000 <= r {
+		return 2
+	}
+	return 1
+}
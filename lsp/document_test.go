@@ -0,0 +1,23 @@
+package lsp_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/lsp"
+)
+
+func ExampleDocument_Position() {
+	doc := lsp.NewDocument("file:///example", 1, "a\r\n𝔸b\nc")
+	fmt.Println(doc.Position(0))
+	fmt.Println(doc.Position(3)) // 𝔸
+	fmt.Println(doc.Position(7)) // b
+	fmt.Println(doc.Position(9)) // c
+	fmt.Println(doc.Offset(lsp.Position{Line: 1, Character: 2}))
+	fmt.Println(doc.Offset(lsp.Position{Line: 1, Character: 9}))
+	// Output:
+	// {0 0}
+	// {1 0}
+	// {1 2}
+	// {2 0}
+	// 7
+	// 8
+}
@@ -0,0 +1,261 @@
+package lsp
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"sort"
+)
+
+// Grammar is a language implementation for any grammar that is built with the
+// ast parser. It reports syntax errors and derives semantic highlighting, the
+// document outline, folding ranges and selection ranges from the spans of the
+// nodes in the parsed tree.
+type Grammar struct {
+	// Language is the name of the language.
+	Language string
+	// Entry parses a complete document. The document is expected to be
+	// consumed entirely.
+	Entry ast.ParseNode
+
+	// TokenKinds maps the type strings of nodes to semantic token types (e.g.
+	// "keyword", "string" or "number"). Nodes with other types are not
+	// highlighted, unless they are part of a node that is. Nested nodes take
+	// precedence over their parents.
+	TokenKinds map[string]string
+	// SymbolKinds maps the type strings of nodes to symbol kinds. Nodes with
+	// these types show up in the document outline.
+	SymbolKinds map[string]SymbolKind
+	// SymbolName returns the name of the symbol of the given node. Defaults to
+	// the value of the first value node within the node.
+	SymbolName func(n *ast.Node) string
+}
+
+// Name returns the name of the language.
+func (g *Grammar) Name() string {
+	return g.Language
+}
+
+// Parse parses the given document. If the document is not valid, a diagnostic
+// is returned that describes the syntax error.
+func (g *Grammar) Parse(doc *Document) (*ast.Node, *Diagnostic) {
+	p, err := ast.New([]byte(doc.Text))
+	if err != nil {
+		// Empty documents can not be parsed.
+		return nil, nil
+	}
+	var f furthest
+	p.SetObserver(&f)
+	n, err := p.Expect(g.Entry)
+	if err == nil {
+		_, err = p.Expect(parser.EOD)
+	}
+	if err != nil {
+		d := f.diagnostic(doc, 0, g.Language)
+		return nil, &d
+	}
+	return n, nil
+}
+
+// Diagnostics returns the syntax error of the document, if any.
+func (g *Grammar) Diagnostics(doc *Document) []Diagnostic {
+	if _, d := g.Parse(doc); d != nil {
+		return []Diagnostic{*d}
+	}
+	return nil
+}
+
+// TokenTypes returns the (sorted) semantic token types that are used.
+func (g *Grammar) TokenTypes() []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range g.TokenKinds {
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SemanticTokens returns the semantic tokens of the nodes with a token type.
+func (g *Grammar) SemanticTokens(doc *Document) []SemanticToken {
+	n, _ := g.Parse(doc)
+	if n == nil {
+		return nil
+	}
+	var tokens []SemanticToken
+	emit := func(start, end int, typ string) {
+		if typ == "" || end <= start {
+			return
+		}
+		if l := len(tokens) - 1; 0 <= l && tokens[l].End == start && tokens[l].Type == typ {
+			tokens[l].End = end
+			return
+		}
+		tokens = append(tokens, SemanticToken{
+			Start: start,
+			End:   end,
+			Type:  typ,
+		})
+	}
+	var walk func(n *ast.Node, typ string)
+	walk = func(n *ast.Node, typ string) {
+		if t, ok := g.TokenKinds[n.TypeString()]; ok {
+			typ = t
+		}
+		offset := n.Start
+		for _, c := range n.Children() {
+			// The parts that are not covered by the children.
+			emit(offset, c.Start, typ)
+			walk(c, typ)
+			offset = c.End
+		}
+		emit(offset, n.End, typ)
+	}
+	walk(n, "")
+	return tokens
+}
+
+// Symbols returns the nodes with a symbol kind as a tree of symbols.
+func (g *Grammar) Symbols(doc *Document) []DocumentSymbol {
+	n, _ := g.Parse(doc)
+	if n == nil {
+		return nil
+	}
+	return g.symbols(doc, n)
+}
+
+// symbols returns the symbols within the given node, including the node itself.
+func (g *Grammar) symbols(doc *Document, n *ast.Node) []DocumentSymbol {
+	var children []DocumentSymbol
+	for _, c := range n.Children() {
+		children = append(children, g.symbols(doc, c)...)
+	}
+	kind, ok := g.SymbolKinds[n.TypeString()]
+	if !ok {
+		return children
+	}
+	name := g.symbolName(n)
+	selection := doc.Range(n.Start, n.End)
+	if id := valueNode(n); id != nil {
+		selection = doc.Range(id.Start, id.End)
+	}
+	return []DocumentSymbol{{
+		Name:           name,
+		Detail:         n.TypeString(),
+		Kind:           kind,
+		Range:          doc.Range(n.Start, n.End),
+		SelectionRange: selection,
+		Children:       children,
+	}}
+}
+
+func (g *Grammar) symbolName(n *ast.Node) string {
+	if g.SymbolName != nil {
+		if name := g.SymbolName(n); name != "" {
+			return name
+		}
+	}
+	if v := valueNode(n); v != nil && v.Value != "" {
+		return v.Value
+	}
+	// Names are not allowed to be empty.
+	return n.TypeString()
+}
+
+// valueNode returns the first value node within the given node.
+func valueNode(n *ast.Node) *ast.Node {
+	if !n.IsParent() {
+		return n
+	}
+	for _, c := range n.Children() {
+		if v := valueNode(c); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// FoldingRanges returns a folding range for every node that spans multiple
+// lines.
+func (g *Grammar) FoldingRanges(doc *Document) []FoldingRange {
+	n, _ := g.Parse(doc)
+	if n == nil {
+		return nil
+	}
+	var (
+		ranges []FoldingRange
+		lines  = make(map[int]bool)
+	)
+	var walk func(n *ast.Node)
+	walk = func(n *ast.Node) {
+		start, end := doc.Position(n.Start).Line, doc.Position(n.End).Line
+		if n.Start < n.End && doc.lines[end] == n.End {
+			// The node ends with a line terminator.
+			end--
+		}
+		// Only the outermost node of a line gets folded.
+		if start < end && !lines[start] {
+			lines[start] = true
+			var kind string
+			if g.TokenKinds[n.TypeString()] == "comment" {
+				kind = "comment"
+			}
+			ranges = append(ranges, FoldingRange{
+				StartLine: start,
+				EndLine:   end,
+				Kind:      kind,
+			})
+		}
+		for _, c := range n.Children() {
+			walk(c)
+		}
+	}
+	walk(n)
+	return ranges
+}
+
+// SelectionRanges returns, for every position, the spans of all the nodes that
+// contain the position, from the innermost to the outermost node.
+func (g *Grammar) SelectionRanges(doc *Document, positions []Position) []SelectionRange {
+	n, _ := g.Parse(doc)
+	ranges := make([]SelectionRange, len(positions))
+	for i, pos := range positions {
+		offset := doc.Offset(pos)
+		// The position itself, when it is not part of any node.
+		selection := SelectionRange{Range: Range{Start: pos, End: pos}}
+		for n := n; n != nil; n = child(n, offset) {
+			if selection.Range == doc.Range(n.Start, n.End) {
+				continue
+			}
+			if n.Start <= offset && offset <= n.End {
+				parent := selection
+				selection = SelectionRange{
+					Range: doc.Range(n.Start, n.End),
+				}
+				if parent.Range.Start != parent.Range.End || parent.Parent != nil {
+					selection.Parent = &parent
+				}
+			}
+		}
+		ranges[i] = selection
+	}
+	return ranges
+}
+
+// child returns the child of the node that contains the given offset.
+func child(n *ast.Node, offset int) *ast.Node {
+	for _, c := range n.Children() {
+		if c.Start <= offset && offset < c.End {
+			return c
+		}
+	}
+	// The offset is right after the last rune of the node.
+	for _, c := range n.Children() {
+		if c.End == offset {
+			return c
+		}
+	}
+	return nil
+}
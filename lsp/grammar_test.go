@@ -0,0 +1,205 @@
+package lsp_test
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/lsp"
+	"github.com/di-wu/parser/op"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+// A small configuration language:
+//
+//	Document <-- (Spacing Block)* Spacing
+//	Block    <-- Name Spacing '{' (Spacing Pair)* Spacing '}'
+//	Pair     <-- Name Spacing '=' Spacing Number
+//	Name     <-- [a-z]+
+//	Number   <-- [0-9]+
+const (
+	documentType = iota + 1
+	blockType
+	pairType
+	nameType
+	numberType
+)
+
+var types = []string{"UNKNOWN", "Document", "Block", "Pair", "Name", "Number"}
+
+var spacing = op.MinZero(parser.CheckRuneFunc(unicode.IsSpace))
+
+func document(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        documentType,
+		TypeStrings: types,
+		Value:       op.And{op.MinZero(op.And{spacing, block}), spacing},
+	})
+}
+
+func block(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        blockType,
+		TypeStrings: types,
+		Value:       op.And{name, spacing, '{', op.MinZero(op.And{spacing, pair}), spacing, '}'},
+	})
+}
+
+func pair(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        pairType,
+		TypeStrings: types,
+		Value:       op.And{name, spacing, '=', spacing, number},
+	})
+}
+
+func name(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        nameType,
+		TypeStrings: types,
+		Value:       op.MinOne(parser.CheckRuneRange('a', 'z')),
+	})
+}
+
+func number(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        numberType,
+		TypeStrings: types,
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+	})
+}
+
+var config = &lsp.Grammar{
+	Language: "config",
+	Entry:    document,
+	TokenKinds: map[string]string{
+		"Name":   "variable",
+		"Number": "number",
+	},
+	SymbolKinds: map[string]lsp.SymbolKind{
+		"Block": lsp.SymbolNamespace,
+		"Pair":  lsp.SymbolField,
+	},
+}
+
+const configText = "server {\n  port = 80\n}\n"
+
+func TestGrammar_Diagnostics(t *testing.T) {
+	for _, test := range []struct {
+		text    string
+		message string
+		rng     lsp.Range
+	}{
+		{configText, "", lsp.Range{}},
+		{"server {\n  port 80\n}\n", "syntax error: unexpected '8', expected '='", rng(1, 7, 8)},
+		{"server {}\n}", "syntax error: unexpected '}', expected end of file", rng(1, 0, 1)},
+		{"server {", "syntax error: unexpected end of file, expected '}'", rng(0, 8, 8)},
+	} {
+		diagnostics := config.Diagnostics(lsp.NewDocument("file:///test", 1, test.text))
+		if test.message == "" {
+			if len(diagnostics) != 0 {
+				t.Error(diagnostics)
+			}
+			continue
+		}
+		if len(diagnostics) != 1 {
+			t.Fatal(diagnostics)
+		}
+		if d := diagnostics[0]; d.Message != test.message || d.Range != test.rng || d.Source != "config" {
+			t.Error(d)
+		}
+	}
+}
+
+func TestGrammar(t *testing.T) {
+	c := lsp.Connect(lsp.NewServer(config))
+	defer c.Close()
+
+	var result lsp.InitializeResult
+	if err := c.Call("initialize", struct{}{}, &result); err != nil {
+		t.Fatal(err)
+	}
+	caps := result.Capabilities
+	if !caps.DocumentSymbolProvider || !caps.FoldingRangeProvider || !caps.SelectionRangeProvider || caps.DefinitionProvider {
+		t.Error(caps)
+	}
+	if caps.SemanticTokensProvider == nil || !reflect.DeepEqual(caps.SemanticTokensProvider.Legend.TokenTypes, []string{"number", "variable"}) {
+		t.Error(caps.SemanticTokensProvider)
+	}
+	if err := c.Notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: "file:///test.config", Text: configText},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var params lsp.PublishDiagnosticsParams
+	if _, err := c.Notification(&params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Diagnostics) != 0 {
+		t.Error(params.Diagnostics)
+	}
+
+	doc := struct {
+		TextDocument lsp.TextDocumentIdentifier `json:"textDocument"`
+		Positions    []lsp.Position             `json:"positions,omitempty"`
+	}{TextDocument: lsp.TextDocumentIdentifier{URI: "file:///test.config"}}
+
+	var tokens lsp.SemanticTokens
+	if err := c.Call("textDocument/semanticTokens/full", doc, &tokens); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens.Data, []int{
+		0, 0, 6, 1, 0, // server
+		1, 2, 4, 1, 0, // port
+		0, 7, 2, 0, 0, // 80
+	}) {
+		t.Error(tokens.Data)
+	}
+
+	var symbols []lsp.DocumentSymbol
+	if err := c.Call("textDocument/documentSymbol", doc, &symbols); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(symbols, []lsp.DocumentSymbol{{
+		Name:           "server",
+		Detail:         "Block",
+		Kind:           lsp.SymbolNamespace,
+		Range:          lsp.Range{Start: lsp.Position{}, End: lsp.Position{Line: 2, Character: 1}},
+		SelectionRange: rng(0, 0, 6),
+		Children: []lsp.DocumentSymbol{{
+			Name:           "port",
+			Detail:         "Pair",
+			Kind:           lsp.SymbolField,
+			Range:          rng(1, 2, 11),
+			SelectionRange: rng(1, 2, 6),
+		}},
+	}}) {
+		t.Error(symbols)
+	}
+
+	var folding []lsp.FoldingRange
+	if err := c.Call("textDocument/foldingRange", doc, &folding); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(folding, []lsp.FoldingRange{{StartLine: 0, EndLine: 2}}) {
+		t.Error(folding)
+	}
+
+	doc.Positions = []lsp.Position{{Line: 1, Character: 3}}
+	var selections []lsp.SelectionRange
+	if err := c.Call("textDocument/selectionRange", doc, &selections); err != nil {
+		t.Fatal(err)
+	}
+	var ranges []lsp.Range
+	for s := &selections[0]; s != nil; s = s.Parent {
+		ranges = append(ranges, s.Range)
+	}
+	if !reflect.DeepEqual(ranges, []lsp.Range{
+		rng(1, 2, 6),  // port
+		rng(1, 2, 11), // port = 80
+		{Start: lsp.Position{}, End: lsp.Position{Line: 2, Character: 1}}, // server { ... }
+		{Start: lsp.Position{}, End: lsp.Position{Line: 3}},               // document
+	}) {
+		t.Error(ranges)
+	}
+}
@@ -0,0 +1,155 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is a JSON-RPC 2.0 message. It is either a request, a response or a
+// notification (a request without an identifier).
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// IsRequest returns whether the message is a request (or notification).
+func (m *Message) IsRequest() bool {
+	return m.Method != ""
+}
+
+// IsNotification returns whether the message is a notification, a request
+// that does not expect a response.
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && m.ID == nil
+}
+
+// ResponseError is the error of a response.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// Error codes defined by JSON-RPC and the language server protocol.
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+
+	ServerNotInitialized = -32002
+	RequestFailed        = -32803
+)
+
+// Conn reads and writes messages that are framed with a header, as described
+// by the base protocol of the language server protocol.
+type Conn struct {
+	r *textproto.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewConn creates a new Conn.
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{
+		r: textproto.NewReader(bufio.NewReader(r)),
+		w: w,
+	}
+}
+
+// Read reads the next message.
+func (c *Conn) Read() (*Message, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, &ResponseError{
+			Code:    ParseError,
+			Message: "invalid content length",
+		}
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(c.r.R, content); err != nil {
+		return nil, err
+	}
+	var m Message
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, &ResponseError{
+			Code:    ParseError,
+			Message: err.Error(),
+		}
+	}
+	return &m, nil
+}
+
+// Write writes the given message. It is safe to write concurrently.
+func (c *Conn) Write(m *Message) error {
+	m.JSONRPC = "2.0"
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(content)
+	return err
+}
+
+// Notify writes a notification with the given method and parameters.
+func (c *Conn) Notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.Write(&Message{
+		Method: method,
+		Params: raw,
+	})
+}
+
+// Reply writes a response to the request with the given identifier. The error
+// gets converted to a ResponseError if it is not one already.
+func (c *Conn) Reply(id json.RawMessage, result interface{}, err error) error {
+	if err != nil {
+		rErr, ok := err.(*ResponseError)
+		if !ok {
+			rErr = &ResponseError{
+				Code:    RequestFailed,
+				Message: err.Error(),
+			}
+		}
+		return c.Write(&Message{
+			ID:    id,
+			Error: rErr,
+		})
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return c.Write(&Message{
+		ID:     id,
+		Result: raw,
+	})
+}
@@ -0,0 +1,308 @@
+package lsp
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/pegn"
+	"regexp"
+	"strings"
+)
+
+// PEGN returns the language implementation for PEGN grammars. It provides
+// diagnostics for syntax errors and undefined rules, go to definition, find
+// references, hover, document symbols and renaming of rules.
+func PEGN() Language {
+	return pegnLanguage{}
+}
+
+type pegnLanguage struct{}
+
+func (pegnLanguage) Name() string {
+	return "pegn"
+}
+
+func (pegnLanguage) Diagnostics(doc *Document) []Diagnostic {
+	return analyzePEGN(doc).diagnostics
+}
+
+func (pegnLanguage) Definition(doc *Document, pos Position) []Location {
+	g := analyzePEGN(doc)
+	name := g.at(doc.Offset(pos))
+	if def, ok := g.definitions[name]; ok {
+		id := def.FirstChild
+		return []Location{doc.Location(id.Start, id.End)}
+	}
+	return nil
+}
+
+func (pegnLanguage) References(doc *Document, pos Position, declaration bool) []Location {
+	g := analyzePEGN(doc)
+	name := g.at(doc.Offset(pos))
+	if name == "" {
+		return nil
+	}
+	var locations []Location
+	for _, n := range g.identifiers {
+		if n.Value != name || (!declaration && n.Type == pegn.IdentifierType) {
+			continue
+		}
+		locations = append(locations, doc.Location(n.Start, n.End))
+	}
+	return locations
+}
+
+func (pegnLanguage) Hover(doc *Document, pos Position) *Hover {
+	g := analyzePEGN(doc)
+	offset := doc.Offset(pos)
+	n := g.node(offset)
+	if n == nil {
+		return nil
+	}
+	r := doc.Range(n.Start, n.End)
+
+	var value string
+	if def, ok := g.definitions[n.Value]; ok {
+		value = fmt.Sprintf("```pegn\n%s\n```", strings.TrimSpace(doc.Text[def.Start:def.End]))
+	} else if _, ok := pegn.Builtin[n.Value]; ok {
+		kind := "token"
+		if strings.ToLower(n.Value) == n.Value {
+			kind = "class"
+		}
+		value = fmt.Sprintf("`%s` is a builtin %s.", n.Value, kind)
+	} else {
+		value = fmt.Sprintf("`%s` is not defined.", n.Value)
+	}
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: value,
+		},
+		Range: &r,
+	}
+}
+
+func (pegnLanguage) Symbols(doc *Document) []DocumentSymbol {
+	g := analyzePEGN(doc)
+	var symbols []DocumentSymbol
+	for _, def := range g.order {
+		var (
+			children   = def.Children()
+			id         = children[0]
+			operator   = children[1]
+			expression = children[2]
+			kind       = SymbolFunction
+		)
+		if operator.Value == "<--" {
+			// Definitions that produce nodes.
+			kind = SymbolClass
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           id.Value,
+			Detail:         operator.Value + " " + doc.Text[expression.Start:expression.End],
+			Kind:           kind,
+			Range:          doc.Range(def.Start, def.End),
+			SelectionRange: doc.Range(id.Start, id.End),
+		})
+	}
+	return symbols
+}
+
+// pegnIdentifier matches valid PEGN identifiers.
+var pegnIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func (pegnLanguage) Rename(doc *Document, pos Position, name string) (*WorkspaceEdit, error) {
+	g := analyzePEGN(doc)
+	old := g.at(doc.Offset(pos))
+	if old == "" {
+		return nil, fmt.Errorf("no rule found at %d:%d", pos.Line, pos.Character)
+	}
+	if _, ok := pegn.Builtin[old]; ok {
+		if _, ok := g.definitions[old]; !ok {
+			return nil, fmt.Errorf("can not rename builtin %s", old)
+		}
+	}
+	if !pegnIdentifier.MatchString(name) {
+		return nil, fmt.Errorf("invalid rule name: %q", name)
+	}
+	if _, ok := g.definitions[name]; ok && name != old {
+		return nil, fmt.Errorf("rule %s is already defined", name)
+	}
+	if _, ok := pegn.Builtin[name]; ok {
+		return nil, fmt.Errorf("rule %s would shadow a builtin", name)
+	}
+
+	var edits []TextEdit
+	for _, n := range g.identifiers {
+		if n.Value == old {
+			edits = append(edits, TextEdit{
+				Range:   doc.Range(n.Start, n.End),
+				NewText: name,
+			})
+		}
+	}
+	return &WorkspaceEdit{
+		Changes: map[string][]TextEdit{
+			doc.URI: edits,
+		},
+	}, nil
+}
+
+// pegnGrammar is the analysis of a PEGN document.
+type pegnGrammar struct {
+	// definitions contains the (first) definition of every rule.
+	definitions map[string]*ast.Node
+	// order contains all the definitions in order of appearance.
+	order []*ast.Node
+	// identifiers contains all the identifier and reference nodes in order of
+	// appearance.
+	identifiers []*ast.Node
+	// namespaces contains the namespaces of the imported grammars.
+	namespaces  map[string]bool
+	diagnostics []Diagnostic
+}
+
+// node returns the identifier or reference node at the given offset.
+func (g *pegnGrammar) node(offset int) *ast.Node {
+	for _, n := range g.identifiers {
+		if n.Start <= offset && offset <= n.End {
+			return n
+		}
+	}
+	return nil
+}
+
+// at returns the name of the rule at the given offset, if any.
+func (g *pegnGrammar) at(offset int) string {
+	if n := g.node(offset); n != nil {
+		return n.Value
+	}
+	return ""
+}
+
+// analyzePEGN parses the given document. Every line that does not start with
+// whitespace starts a new chunk (definition or comment) which gets parsed on
+// its own, so a syntax error only affects a single definition.
+func analyzePEGN(doc *Document) *pegnGrammar {
+	g := pegnGrammar{
+		definitions: make(map[string]*ast.Node),
+		namespaces:  make(map[string]bool),
+	}
+	for _, chunk := range chunks(doc.Text) {
+		start, end := chunk[0], chunk[1]
+		p, err := ast.New([]byte(doc.Text[start:end]))
+		if err != nil {
+			continue
+		}
+		f := furthest{chunk: true}
+		p.SetObserver(&f)
+		n, err := p.Expect(pegn.Grammar)
+		if err != nil {
+			g.diagnostics = append(g.diagnostics, f.diagnostic(doc, start, "pegn"))
+			continue
+		}
+		shift(n, start)
+		for _, def := range n.Children() {
+			switch def.Type {
+			case pegn.DefinitionType:
+				g.define(doc, def)
+			case pegn.ImportType:
+				g.namespaces[def.FirstChild.Value] = true
+			}
+		}
+	}
+
+	// Check for undefined references.
+	for _, n := range g.identifiers {
+		if n.Type != pegn.ReferenceType {
+			continue
+		}
+		if _, ok := g.definitions[n.Value]; ok {
+			continue
+		}
+		if _, ok := pegn.Builtin[n.Value]; ok {
+			continue
+		}
+		// The rules of imported grammars are not checked.
+		if i := strings.Index(n.Value, "."); i != -1 && g.namespaces[n.Value[:i]] {
+			continue
+		}
+		g.diagnostics = append(g.diagnostics, Diagnostic{
+			Range:    doc.Range(n.Start, n.End),
+			Severity: SeverityError,
+			Source:   "pegn",
+			Message:  fmt.Sprintf("undefined rule: %s", n.Value),
+		})
+	}
+	return &g
+}
+
+// define adds the given definition to the grammar.
+func (g *pegnGrammar) define(doc *Document, def *ast.Node) {
+	id := def.FirstChild
+	if _, ok := g.definitions[id.Value]; ok {
+		g.diagnostics = append(g.diagnostics, Diagnostic{
+			Range:    doc.Range(id.Start, id.End),
+			Severity: SeverityError,
+			Source:   "pegn",
+			Message:  fmt.Sprintf("rule %s is already defined", id.Value),
+		})
+	} else {
+		g.definitions[id.Value] = def
+	}
+	g.order = append(g.order, def)
+
+	var walk func(n *ast.Node)
+	walk = func(n *ast.Node) {
+		switch n.Type {
+		case pegn.IdentifierType, pegn.ReferenceType:
+			g.identifiers = append(g.identifiers, n)
+		}
+		for _, c := range n.Children() {
+			walk(c)
+		}
+	}
+	walk(def)
+}
+
+// chunks splits the text in chunks, every line that does not start with
+// whitespace starts a new chunk.
+func chunks(text string) [][2]int {
+	var (
+		chunks [][2]int
+		start  = -1
+	)
+	for i := 0; i < len(text); {
+		end := strings.IndexAny(text[i:], "\r\n")
+		if end == -1 {
+			end = len(text)
+		} else {
+			end += i + 1
+			if text[end-1] == '\r' && end < len(text) && text[end] == '\n' {
+				end++
+			}
+		}
+		switch text[i] {
+		case ' ', '\t', '\r', '\n':
+			// Continuation of the previous chunk.
+		default:
+			if start != -1 {
+				chunks = append(chunks, [2]int{start, i})
+			}
+			start = i
+		}
+		i = end
+	}
+	if start != -1 {
+		chunks = append(chunks, [2]int{start, len(text)})
+	}
+	return chunks
+}
+
+// shift shifts the offsets of the node (and its children).
+func shift(n *ast.Node, offset int) {
+	n.Start += offset
+	n.End += offset
+	for _, c := range n.Children() {
+		shift(c, offset)
+	}
+}
@@ -0,0 +1,235 @@
+package lsp_test
+
+import (
+	"github.com/di-wu/parser/lsp"
+	"reflect"
+	"testing"
+)
+
+const grammar = `# CALC (v0.1.1) github.com/di-wu/parser/examples/calculator
+
+Expr    <-- Integer (Op Integer)*
+Op      <-- '+' / '-' # operators
+Integer <-- [0-9]+ / Paren
+`
+
+// open starts a PEGN server, opens the given document and returns the
+// published diagnostics.
+func open(t *testing.T, text string) (*lsp.Client, []lsp.Diagnostic) {
+	c := lsp.Connect(lsp.NewServer(lsp.PEGN()))
+	var result lsp.InitializeResult
+	if err := c.Call("initialize", struct{}{}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if caps := result.Capabilities; !caps.DefinitionProvider || !caps.RenameProvider {
+		t.Error(caps)
+	}
+	if err := c.Notify("initialized", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:        "file:///calc.pegn",
+			LanguageID: "pegn",
+			Version:    1,
+			Text:       text,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var params lsp.PublishDiagnosticsParams
+	method, err := c.Notification(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "textDocument/publishDiagnostics" || params.URI != "file:///calc.pegn" {
+		t.Error(method, params)
+	}
+	return c, params.Diagnostics
+}
+
+func position(line, character int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: "file:///calc.pegn"},
+		Position:     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func rng(line, start, end int) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: line, Character: start},
+		End:   lsp.Position{Line: line, Character: end},
+	}
+}
+
+func TestPEGN_diagnostics(t *testing.T) {
+	c, diagnostics := open(t, grammar+"Broken  <-- 'a' )\n")
+	defer c.Close()
+
+	if len(diagnostics) != 2 {
+		t.Fatal(diagnostics)
+	}
+	if d := diagnostics[0]; d.Range != rng(5, 16, 17) || d.Message != `syntax error: unexpected ')', expected end of line, '&', '!', 'x', '\'', '[', '(', '/' or '#'` {
+		t.Error(d)
+	}
+	if d := diagnostics[1]; d.Range != rng(4, 21, 26) || d.Message != "undefined rule: Paren" {
+		t.Error(d)
+	}
+
+	// Fix the errors.
+	if err := c.Notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			URI:     "file:///calc.pegn",
+			Version: 2,
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{Text: grammar + "Paren    <- '(' Expr ')'\n"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var params lsp.PublishDiagnosticsParams
+	if _, err := c.Notification(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Version != 2 || len(params.Diagnostics) != 0 {
+		t.Error(params)
+	}
+}
+
+func TestPEGN_imports(t *testing.T) {
+	c, diagnostics := open(t, "import json 'json.pegn'\n\nDocument   <-- json.Value\njson.Value  <- Comment? json.Value / xml.Value\n")
+	defer c.Close()
+
+	// The references to the imported grammar are not checked.
+	if len(diagnostics) != 2 {
+		t.Fatal(diagnostics)
+	}
+	if d := diagnostics[0]; d.Range != rng(3, 15, 22) || d.Message != "undefined rule: Comment" {
+		t.Error(d)
+	}
+	if d := diagnostics[1]; d.Range != rng(3, 37, 46) || d.Message != "undefined rule: xml.Value" {
+		t.Error(d)
+	}
+}
+
+func TestPEGN_definition(t *testing.T) {
+	c, _ := open(t, grammar)
+	defer c.Close()
+
+	var locations []lsp.Location
+	// Op in "Expr <-- Integer (Op Integer)*"
+	if err := c.Call("textDocument/definition", position(2, 22), &locations); err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 1 || locations[0].Range != rng(3, 0, 2) {
+		t.Error(locations)
+	}
+
+	// Builtins and undefined rules have no definition.
+	if err := c.Call("textDocument/definition", position(4, 30), &locations); err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 0 {
+		t.Error(locations)
+	}
+}
+
+func TestPEGN_references(t *testing.T) {
+	c, _ := open(t, grammar)
+	defer c.Close()
+
+	params := lsp.ReferenceParams{TextDocumentPositionParams: position(4, 3)}
+	var locations []lsp.Location
+	if err := c.Call("textDocument/references", params, &locations); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(locations, []lsp.Location{
+		{URI: "file:///calc.pegn", Range: rng(2, 12, 19)},
+		{URI: "file:///calc.pegn", Range: rng(2, 24, 31)},
+	}) {
+		t.Error(locations)
+	}
+
+	params.Context.IncludeDeclaration = true
+	if err := c.Call("textDocument/references", params, &locations); err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 3 || locations[2].Range != rng(4, 0, 7) {
+		t.Error(locations)
+	}
+}
+
+func TestPEGN_hover(t *testing.T) {
+	c, _ := open(t, grammar)
+	defer c.Close()
+
+	var hover lsp.Hover
+	if err := c.Call("textDocument/hover", position(2, 21), &hover); err != nil {
+		t.Fatal(err)
+	}
+	if hover.Contents.Value != "```pegn\nOp      <-- '+' / '-' # operators\n```" {
+		t.Error(hover.Contents.Value)
+	}
+	if *hover.Range != rng(2, 21, 23) {
+		t.Error(hover.Range)
+	}
+
+	var none *lsp.Hover
+	if err := c.Call("textDocument/hover", position(1, 0), &none); err != nil {
+		t.Fatal(err)
+	}
+	if none != nil {
+		t.Error(none)
+	}
+}
+
+func TestPEGN_symbols(t *testing.T) {
+	c, _ := open(t, grammar)
+	defer c.Close()
+
+	var symbols []lsp.DocumentSymbol
+	if err := c.Call("textDocument/documentSymbol", struct {
+		TextDocument lsp.TextDocumentIdentifier `json:"textDocument"`
+	}{lsp.TextDocumentIdentifier{URI: "file:///calc.pegn"}}, &symbols); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Expr", "Op", "Integer"}) {
+		t.Error(names)
+	}
+	if s := symbols[1]; s.Detail != "<-- '+' / '-'" || s.Kind != lsp.SymbolClass || s.SelectionRange != rng(3, 0, 2) {
+		t.Error(s)
+	}
+}
+
+func TestPEGN_rename(t *testing.T) {
+	c, _ := open(t, grammar)
+	defer c.Close()
+
+	var edit lsp.WorkspaceEdit
+	if err := c.Call("textDocument/rename", lsp.RenameParams{
+		TextDocumentPositionParams: position(3, 1),
+		NewName:                    "Operator",
+	}, &edit); err != nil {
+		t.Fatal(err)
+	}
+	if edits := edit.Changes["file:///calc.pegn"]; !reflect.DeepEqual(edits, []lsp.TextEdit{
+		{Range: rng(2, 21, 23), NewText: "Operator"},
+		{Range: rng(3, 0, 2), NewText: "Operator"},
+	}) {
+		t.Error(edits)
+	}
+
+	for _, name := range []string{"Expr", "0p", "SP"} {
+		if err := c.Call("textDocument/rename", lsp.RenameParams{
+			TextDocumentPositionParams: position(3, 1),
+			NewName:                    name,
+		}, &edit); err == nil {
+			t.Error(name)
+		}
+	}
+}
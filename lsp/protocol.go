@@ -0,0 +1,242 @@
+package lsp
+
+// Position in a text document, expressed as a zero based line and a zero based
+// character offset (in UTF-16 code units) within that line.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range in a text document, the end is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location inside a resource.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// DiagnosticSeverity indicates the severity of a diagnostic.
+type DiagnosticSeverity int
+
+const (
+	SeverityError DiagnosticSeverity = iota + 1
+	SeverityWarning
+	SeverityInformation
+	SeverityHint
+)
+
+// Diagnostic represents a problem, like a compiler error or warning.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// PublishDiagnosticsParams are the parameters of the
+// "textDocument/publishDiagnostics" notification.
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     int          `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// TextDocumentItem is a text document transferred from the client to the
+// server.
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextDocumentIdentifier identifies a text document.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier identifies a specific version of a text
+// document.
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentPositionParams are the parameters of requests that refer to a
+// position within a text document.
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// DidOpenTextDocumentParams are the parameters of the "textDocument/didOpen"
+// notification.
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// DidChangeTextDocumentParams are the parameters of the
+// "textDocument/didChange" notification. Only full document synchronization is
+// supported, so the last change contains the whole document.
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// TextDocumentContentChangeEvent is a change of a text document.
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+// DidCloseTextDocumentParams are the parameters of the "textDocument/didClose"
+// notification.
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// ReferenceParams are the parameters of the "textDocument/references" request.
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+// MarkupContent represents a string value which content can be represented in
+// different formats.
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the result of a "textDocument/hover" request.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// SymbolKind indicates the kind of a symbol.
+type SymbolKind int
+
+const (
+	SymbolFile SymbolKind = iota + 1
+	SymbolModule
+	SymbolNamespace
+	SymbolPackage
+	SymbolClass
+	SymbolMethod
+	SymbolProperty
+	SymbolField
+	SymbolConstructor
+	SymbolEnum
+	SymbolInterface
+	SymbolFunction
+	SymbolVariable
+	SymbolConstant
+	SymbolString
+	SymbolNumber
+	SymbolBoolean
+	SymbolArray
+	SymbolObject
+	SymbolKey
+	SymbolNull
+	SymbolEnumMember
+	SymbolStruct
+	SymbolEvent
+	SymbolOperator
+	SymbolTypeParameter
+)
+
+// DocumentSymbol represents programming constructs like variables, classes,
+// interfaces etc. that appear in a document.
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// RenameParams are the parameters of the "textDocument/rename" request.
+type RenameParams struct {
+	TextDocumentPositionParams
+	NewName string `json:"newName"`
+}
+
+// TextEdit is a textual edit applicable to a text document.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit represents changes to many resources managed in the workspace.
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// SemanticTokensLegend contains the token types and modifiers the server uses
+// to encode semantic tokens.
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// SemanticTokensOptions are the semantic token capabilities of a server.
+type SemanticTokensOptions struct {
+	Legend SemanticTokensLegend `json:"legend"`
+	Full   bool                 `json:"full"`
+}
+
+// SemanticTokens is the result of a "textDocument/semanticTokens/full"
+// request. Every token is encoded as five integers: the line (relative to the
+// previous token), the start character (relative to the previous token if on
+// the same line), the length, the token type and the token modifiers.
+type SemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+// FoldingRange represents a range of lines that can be folded.
+type FoldingRange struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+// SelectionRange represents a range that can be selected, the parent range
+// contains it and is selected next when expanding the selection.
+type SelectionRange struct {
+	Range  Range           `json:"range"`
+	Parent *SelectionRange `json:"parent,omitempty"`
+}
+
+// InitializeResult is the result of the "initialize" request.
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   *ServerInfo        `json:"serverInfo,omitempty"`
+}
+
+// ServerInfo contains information about the server.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ServerCapabilities defines the capabilities provided by the server.
+type ServerCapabilities struct {
+	// TextDocumentSync is always 1, the full document gets synchronized.
+	TextDocumentSync       int  `json:"textDocumentSync"`
+	DefinitionProvider     bool `json:"definitionProvider,omitempty"`
+	ReferencesProvider     bool `json:"referencesProvider,omitempty"`
+	HoverProvider          bool `json:"hoverProvider,omitempty"`
+	DocumentSymbolProvider bool `json:"documentSymbolProvider,omitempty"`
+	RenameProvider         bool `json:"renameProvider,omitempty"`
+
+	SemanticTokensProvider *SemanticTokensOptions `json:"semanticTokensProvider,omitempty"`
+	FoldingRangeProvider   bool                   `json:"foldingRangeProvider,omitempty"`
+	SelectionRangeProvider bool                   `json:"selectionRangeProvider,omitempty"`
+}
@@ -0,0 +1,390 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Language implements the language specific features of a server. Additional
+// features can be provided by implementing the (optional) provider interfaces,
+// e.g. DefinitionProvider.
+type Language interface {
+	// Name returns the name of the language (server).
+	Name() string
+	// Diagnostics returns the problems found in the given document.
+	Diagnostics(doc *Document) []Diagnostic
+}
+
+// DefinitionProvider provides the locations of the definition of the symbol at
+// the given position.
+type DefinitionProvider interface {
+	Definition(doc *Document, pos Position) []Location
+}
+
+// ReferencesProvider provides the locations of all the references to the
+// symbol at the given position.
+type ReferencesProvider interface {
+	References(doc *Document, pos Position, declaration bool) []Location
+}
+
+// HoverProvider provides information about the symbol at the given position.
+type HoverProvider interface {
+	Hover(doc *Document, pos Position) *Hover
+}
+
+// SymbolProvider provides the symbols of the given document.
+type SymbolProvider interface {
+	Symbols(doc *Document) []DocumentSymbol
+}
+
+// RenameProvider renames the symbol at the given position.
+type RenameProvider interface {
+	Rename(doc *Document, pos Position, name string) (*WorkspaceEdit, error)
+}
+
+// SemanticToken is a token that gets highlighted. The offsets are in bytes, the
+// end is exclusive.
+type SemanticToken struct {
+	Start, End int
+	// Type is one of the token types of the SemanticTokensProvider.
+	Type string
+}
+
+// SemanticTokensProvider provides the tokens used for semantic highlighting.
+type SemanticTokensProvider interface {
+	// TokenTypes returns all the token types that are used.
+	TokenTypes() []string
+	// SemanticTokens returns the tokens of the given document, ordered by
+	// their offsets. Tokens are not allowed to overlap.
+	SemanticTokens(doc *Document) []SemanticToken
+}
+
+// FoldingRangeProvider provides the ranges that can be folded.
+type FoldingRangeProvider interface {
+	FoldingRanges(doc *Document) []FoldingRange
+}
+
+// SelectionRangeProvider provides the selection ranges at the given positions.
+type SelectionRangeProvider interface {
+	SelectionRanges(doc *Document, positions []Position) []SelectionRange
+}
+
+// Server is a language server that communicates over JSON-RPC. It handles the
+// life cycle and the synchronization of documents, the language specific
+// requests are delegated to the language.
+type Server struct {
+	lang Language
+	conn *Conn
+	docs map[string]*Document
+
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a new Server for the given language.
+func NewServer(lang Language) *Server {
+	return &Server{
+		lang: lang,
+		docs: make(map[string]*Document),
+	}
+}
+
+// Serve reads requests from the reader and writes the responses to the writer
+// until the client exits or the reader is closed. The standard input and
+// output are commonly used.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = NewConn(r, w)
+	for {
+		m, err := s.conn.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if rErr, ok := err.(*ResponseError); ok {
+				if err := s.conn.Reply(json.RawMessage("null"), nil, rErr); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+		if !m.IsRequest() {
+			// Responses to server requests are not supported.
+			continue
+		}
+		if m.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(m)
+		if m.IsNotification() {
+			continue
+		}
+		if err := s.conn.Reply(m.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m *Message) (interface{}, error) {
+	switch m.Method {
+	case "initialize":
+		s.initialized = true
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	}
+	if !s.initialized {
+		return nil, &ResponseError{
+			Code:    ServerNotInitialized,
+			Message: "server not initialized",
+		}
+	}
+	if s.shutdown {
+		return nil, &ResponseError{
+			Code:    InvalidRequest,
+			Message: "server is shutting down",
+		}
+	}
+
+	switch m.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return nil, err
+		}
+		item := params.TextDocument
+		return nil, s.update(NewDocument(item.URI, item.Version, item.Text))
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.update(NewDocument(params.TextDocument.URI, params.TextDocument.Version, text))
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	}
+	return s.request(m)
+}
+
+// request handles the language specific requests.
+func (s *Server) request(m *Message) (interface{}, error) {
+	var supported bool
+	switch m.Method {
+	case "textDocument/definition":
+		_, supported = s.lang.(DefinitionProvider)
+	case "textDocument/references":
+		_, supported = s.lang.(ReferencesProvider)
+	case "textDocument/hover":
+		_, supported = s.lang.(HoverProvider)
+	case "textDocument/documentSymbol":
+		_, supported = s.lang.(SymbolProvider)
+	case "textDocument/rename":
+		_, supported = s.lang.(RenameProvider)
+	case "textDocument/semanticTokens/full":
+		_, supported = s.lang.(SemanticTokensProvider)
+	case "textDocument/foldingRange":
+		_, supported = s.lang.(FoldingRangeProvider)
+	case "textDocument/selectionRange":
+		_, supported = s.lang.(SelectionRangeProvider)
+	}
+	if !supported {
+		return nil, &ResponseError{
+			Code:    MethodNotFound,
+			Message: "method not supported: " + m.Method,
+		}
+	}
+
+	var params struct {
+		TextDocumentPositionParams
+		NewName   string     `json:"newName"`
+		Positions []Position `json:"positions"`
+		Context   struct {
+			IncludeDeclaration bool `json:"includeDeclaration"`
+		} `json:"context"`
+	}
+	if err := unmarshal(m.Params, &params); err != nil {
+		return nil, err
+	}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, &ResponseError{
+			Code:    InvalidParams,
+			Message: "unknown document: " + params.TextDocument.URI,
+		}
+	}
+
+	switch pos := params.Position; m.Method {
+	case "textDocument/definition":
+		return orEmpty(s.lang.(DefinitionProvider).Definition(doc, pos)), nil
+	case "textDocument/references":
+		return orEmpty(s.lang.(ReferencesProvider).References(doc, pos, params.Context.IncludeDeclaration)), nil
+	case "textDocument/hover":
+		return s.lang.(HoverProvider).Hover(doc, pos), nil
+	case "textDocument/documentSymbol":
+		symbols := s.lang.(SymbolProvider).Symbols(doc)
+		if symbols == nil {
+			symbols = []DocumentSymbol{}
+		}
+		return symbols, nil
+	case "textDocument/rename":
+		return s.lang.(RenameProvider).Rename(doc, pos, params.NewName)
+	case "textDocument/semanticTokens/full":
+		return s.semanticTokens(doc), nil
+	case "textDocument/foldingRange":
+		ranges := s.lang.(FoldingRangeProvider).FoldingRanges(doc)
+		if ranges == nil {
+			ranges = []FoldingRange{}
+		}
+		return ranges, nil
+	default: // textDocument/selectionRange
+		ranges := s.lang.(SelectionRangeProvider).SelectionRanges(doc, params.Positions)
+		if ranges == nil {
+			ranges = []SelectionRange{}
+		}
+		return ranges, nil
+	}
+}
+
+func (s *Server) initialize() InitializeResult {
+	_, definition := s.lang.(DefinitionProvider)
+	_, references := s.lang.(ReferencesProvider)
+	_, hover := s.lang.(HoverProvider)
+	_, symbols := s.lang.(SymbolProvider)
+	_, rename := s.lang.(RenameProvider)
+	_, folding := s.lang.(FoldingRangeProvider)
+	_, selection := s.lang.(SelectionRangeProvider)
+	result := InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       1,
+			DefinitionProvider:     definition,
+			ReferencesProvider:     references,
+			HoverProvider:          hover,
+			DocumentSymbolProvider: symbols,
+			RenameProvider:         rename,
+			FoldingRangeProvider:   folding,
+			SelectionRangeProvider: selection,
+		},
+		ServerInfo: &ServerInfo{
+			Name: s.lang.Name(),
+		},
+	}
+	if p, ok := s.lang.(SemanticTokensProvider); ok {
+		result.Capabilities.SemanticTokensProvider = &SemanticTokensOptions{
+			Legend: SemanticTokensLegend{
+				TokenTypes:     p.TokenTypes(),
+				TokenModifiers: []string{},
+			},
+			Full: true,
+		}
+	}
+	return result
+}
+
+// semanticTokens encodes the semantic tokens of the given document. Tokens that
+// span multiple lines are split up, since not all clients support them.
+func (s *Server) semanticTokens(doc *Document) SemanticTokens {
+	p := s.lang.(SemanticTokensProvider)
+	types := make(map[string]int)
+	for i, t := range p.TokenTypes() {
+		types[t] = i
+	}
+
+	var (
+		data = []int{}
+		prev Position
+	)
+	for _, t := range p.SemanticTokens(doc) {
+		typ, ok := types[t.Type]
+		if !ok {
+			continue
+		}
+		for start := t.Start; start < t.End; {
+			pos := doc.Position(start)
+			end := lineEnd(doc, pos.Line)
+			if t.End < end {
+				end = t.End
+			}
+			var length int
+			for _, r := range doc.Text[start:end] {
+				length += utf16Len(r)
+			}
+			if 0 < length {
+				character := pos.Character
+				if pos.Line == prev.Line {
+					character -= prev.Character
+				}
+				data = append(data, pos.Line-prev.Line, character, length, typ, 0)
+				prev = pos
+			}
+			if len(doc.lines) <= pos.Line+1 {
+				break
+			}
+			start = doc.lines[pos.Line+1]
+		}
+	}
+	return SemanticTokens{Data: data}
+}
+
+// lineEnd returns the offset of the end of the given line, excluding the line
+// terminator.
+func lineEnd(doc *Document, line int) int {
+	end := len(doc.Text)
+	if line+1 < len(doc.lines) {
+		end = doc.lines[line+1]
+	}
+	for doc.lines[line] < end && (doc.Text[end-1] == '\n' || doc.Text[end-1] == '\r') {
+		end--
+	}
+	return end
+}
+
+// update stores the document and publishes its diagnostics.
+func (s *Server) update(doc *Document) error {
+	s.docs[doc.URI] = doc
+	diagnostics := s.lang.Diagnostics(doc)
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.URI,
+		Version:     doc.Version,
+		Diagnostics: diagnostics,
+	})
+}
+
+func unmarshal(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &ResponseError{
+			Code:    InvalidParams,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// orEmpty makes sure an empty list of locations is not encoded as null.
+func orEmpty(locations []Location) []Location {
+	if locations == nil {
+		return []Location{}
+	}
+	return locations
+}
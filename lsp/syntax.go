@@ -0,0 +1,120 @@
+package lsp
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// furthest is an observer that keeps track of the parse errors that occurred
+// the furthest in the data. This is commonly the best location to report a
+// syntax error of a PEG based parser.
+type furthest struct {
+	// chunk indicates that the parsed data is only a part of the document, so
+	// the end of the data is not the end of the document.
+	chunk bool
+
+	offset   int
+	expected []string
+}
+
+func (f *furthest) Enter(interface{}, *parser.Cursor) {}
+
+func (f *furthest) Exit(i interface{}, at *parser.Cursor, _ *ast.Node, err error) {
+	e, ok := err.(*parser.ExpectedParseError)
+	if !ok {
+		return
+	}
+
+	// Only terminals are described, classes only move the offset.
+	offset := e.Conflict.Offset()
+	var expected string
+	switch v := e.Expected.(type) {
+	case rune:
+		switch v {
+		case parser.EOD:
+			if f.chunk {
+				return
+			}
+			expected = "end of file"
+		case ' ', '\t':
+			// Spacing is allowed almost everywhere.
+			return
+		case '\n', '\r':
+			expected = "end of line"
+		default:
+			expected = strconv.QuoteRune(v)
+		}
+	case string:
+		if v == "\r\n" {
+			expected = "end of line"
+		} else {
+			expected = strconv.Quote(v)
+		}
+	case parser.CharSet, parser.UnicodeClass, *unicode.RangeTable, op.Quoted:
+		expected = parser.Stringer(v)
+	case parser.AnonymousClass, parser.Class:
+		// The conflict of a class points to the rune after the one that did
+		// not match, the parser got reset to the latter.
+		offset = at.Offset()
+	default:
+		return
+	}
+
+	if offset < f.offset {
+		return
+	}
+	if f.offset < offset {
+		f.offset = offset
+		f.expected = nil
+	}
+	if expected == "" {
+		return
+	}
+	for _, e := range f.expected {
+		if e == expected {
+			return
+		}
+	}
+	f.expected = append(f.expected, expected)
+}
+
+// diagnostic converts the furthest error to a diagnostic, the offset is the
+// offset of the parsed data within the document.
+func (f *furthest) diagnostic(doc *Document, offset int, source string) Diagnostic {
+	start, end := offset+f.offset, offset+f.offset
+	got := "end of file"
+	if start < len(doc.Text) {
+		r, size := utf8.DecodeRuneInString(doc.Text[start:])
+		switch r {
+		case '\n', '\r':
+			got = "end of line"
+		default:
+			got = strconv.QuoteRune(r)
+		}
+		end += size
+	}
+	message := fmt.Sprintf("syntax error: unexpected %s", got)
+	switch len(f.expected) {
+	case 0:
+	case 1:
+		message += fmt.Sprintf(", expected %s", f.expected[0])
+	default:
+		message += fmt.Sprintf(
+			", expected %s or %s",
+			strings.Join(f.expected[:len(f.expected)-1], ", "),
+			f.expected[len(f.expected)-1],
+		)
+	}
+	return Diagnostic{
+		Range:    doc.Range(start, end),
+		Severity: SeverityError,
+		Source:   source,
+		Message:  message,
+	}
+}
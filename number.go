@@ -0,0 +1,369 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat describes the syntax of numeric literals.
+type NumberFormat struct {
+	// Bases contains the bases in which integers can be written, defaults to
+	// base 10. Integers in other bases are prefixed: 0x (16), 0o (8) or 0b (2).
+	// Floats are always written in base 10.
+	Bases []int
+	// Separator, if not zero, can be used in between digits, e.g. '_' allows
+	// 1_000_000 and 0xFF_FF.
+	Separator rune
+	// LeadingZeros indicates whether base 10 numbers can start with zeros,
+	// e.g. 007.
+	LeadingZeros bool
+	// PlusSign indicates whether numbers can start with a plus sign. Signed
+	// numbers can always start with a minus sign.
+	PlusSign bool
+}
+
+// prefixes contains the prefixes of the bases other than 10.
+var prefixes = map[rune]int{
+	'x': 16, 'X': 16,
+	'o': 8, 'O': 8,
+	'b': 2, 'B': 2,
+}
+
+// number is a scanned numeric literal.
+type number struct {
+	// literal contains the sign and the digits, without the prefix and
+	// separators. This is the format strconv expects.
+	literal string
+	base    int
+}
+
+// scan consumes the longest numeric literal it can. It returns the cursor of
+// the last rune of the literal, nil if none got consumed.
+func (f NumberFormat) scan(p *Parser, signed, float bool) (number, *Cursor, bool) {
+	var (
+		b    strings.Builder
+		last *Cursor
+	)
+	// next consumes the current rune.
+	next := func(write bool) {
+		if write {
+			b.WriteRune(p.Current())
+		}
+		last = p.Mark()
+		p.Next()
+	}
+	switch r := p.Current(); {
+	case r == '-' && signed, r == '+' && f.PlusSign:
+		next(true)
+	}
+
+	n := number{base: 10}
+	if p.Current() == '0' && !float {
+		if base, ok := prefixes[p.Peek().Rune]; ok && f.hasBase(base) {
+			start := p.Mark()
+			p.Next()
+			p.Next()
+			if !f.digits(p, base, &b, &last) {
+				// Only a zero, e.g. "0x" of "0xG".
+				p.Jump(start)
+			} else {
+				n.base, n.literal = base, b.String()
+				return n, last, true
+			}
+		}
+	}
+	if !f.hasBase(10) && !float {
+		return n, last, false
+	}
+	if !f.digits(p, 10, &b, &last) {
+		return n, last, false
+	}
+	digits := strings.TrimLeft(b.String(), "+-")
+	if !f.LeadingZeros && 1 < len(digits) && digits[0] == '0' {
+		return n, last, false
+	}
+	if float {
+		// Fraction, only if followed by a digit.
+		if p.Current() == '.' && isDigit(p.Peek().Rune, 10) {
+			next(true)
+			f.digits(p, 10, &b, &last)
+		}
+		// Exponent, only if followed by digits.
+		if r := p.Current(); r == 'e' || r == 'E' {
+			start, end, mantissa := p.Mark(), last, b.String()
+			next(true)
+			if r := p.Current(); r == '+' || r == '-' {
+				next(true)
+			}
+			if !f.digits(p, 10, &b, &last) {
+				p.Jump(start)
+				last = end
+				b.Reset()
+				b.WriteString(mantissa)
+			}
+		}
+	}
+	n.literal = b.String()
+	return n, last, true
+}
+
+// digits consumes one or more digits of the given base, with separators in
+// between them. Returns false if there is no digit.
+func (f NumberFormat) digits(p *Parser, base int, b *strings.Builder, last **Cursor) bool {
+	if !isDigit(p.Current(), base) {
+		return false
+	}
+	for {
+		b.WriteRune(p.Current())
+		*last = p.Mark()
+		p.Next()
+		if f.Separator != 0 && p.Current() == f.Separator && isDigit(p.Peek().Rune, base) {
+			p.Next()
+		}
+		if !isDigit(p.Current(), base) {
+			return true
+		}
+	}
+}
+
+func (f NumberFormat) hasBase(base int) bool {
+	if len(f.Bases) == 0 {
+		return base == 10
+	}
+	for _, b := range f.Bases {
+		if b == base {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigit(r rune, base int) bool {
+	switch {
+	case '0' <= r && r <= '9':
+		return int(r-'0') < base
+	case 'a' <= r && r <= 'f':
+		return base == 16
+	case 'A' <= r && r <= 'F':
+		return base == 16
+	}
+	return false
+}
+
+// parseNumber parses the given literal with the scan function. An error is
+// returned if the literal is not a number of the format.
+func parseNumber(s string, scan func(p *Parser) (number, *Cursor, bool)) (number, error) {
+	p, err := New([]byte(s))
+	if err != nil {
+		return number{}, fmt.Errorf("invalid number %q", s)
+	}
+	n, _, ok := scan(p)
+	if !ok || !p.Done() {
+		return number{}, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+// CheckInt returns a class that checks whether the following runes are a
+// (signed) integer within the given range (inclusive). The format of the
+// integer can be changed by setting the NumberFormat of the class.
+func CheckInt(min, max int64) IntClass {
+	return IntClass{
+		Min: min,
+		Max: max,
+	}
+}
+
+// IntClass is a class that matches a signed integer, see CheckInt.
+type IntClass struct {
+	NumberFormat
+	// Min and Max are the bounds of the value (inclusive).
+	Min, Max int64
+	// Key, if not nil, is used to store the value of the integer as an int64
+	// in the parser (see Parser.SetValue).
+	Key interface{}
+}
+
+// Check checks whether the following runes are an integer within the range.
+// It consumes all the digits it possibly can, e.g. "300" does not match the
+// range [0, 255], even though its prefix "30" does.
+func (c IntClass) Check(p *Parser) (*Cursor, bool) {
+	n, last, ok := c.scan(p, true, false)
+	if !ok {
+		return last, false
+	}
+	v, err := c.value(n)
+	if err != nil {
+		return last, false
+	}
+	if c.Key != nil {
+		p.SetValue(c.Key, v)
+	}
+	return last, true
+}
+
+// Parse returns the value of the given integer, or an error if it does not
+// match the class.
+func (c IntClass) Parse(s string) (int64, error) {
+	n, err := parseNumber(s, func(p *Parser) (number, *Cursor, bool) {
+		return c.scan(p, true, false)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.value(n)
+}
+
+func (c IntClass) value(n number) (int64, error) {
+	v, err := strconv.ParseInt(n.literal, n.base, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < c.Min || c.Max < v {
+		return 0, fmt.Errorf("%d is not in the range [%d, %d]", v, c.Min, c.Max)
+	}
+	return v, nil
+}
+
+func (c IntClass) String() string {
+	return fmt.Sprintf("integer [%d, %d]", c.Min, c.Max)
+}
+
+// CheckUint returns a class that checks whether the following runes are an
+// unsigned integer within the given range (inclusive). The format of the
+// integer can be changed by setting the NumberFormat of the class.
+func CheckUint(min, max uint64) UintClass {
+	return UintClass{
+		Min: min,
+		Max: max,
+	}
+}
+
+// UintClass is a class that matches an unsigned integer, see CheckUint.
+type UintClass struct {
+	NumberFormat
+	// Min and Max are the bounds of the value (inclusive).
+	Min, Max uint64
+	// Key, if not nil, is used to store the value of the integer as an uint64
+	// in the parser (see Parser.SetValue).
+	Key interface{}
+}
+
+// Check checks whether the following runes are an unsigned integer within the
+// range. It consumes all the digits it possibly can.
+func (c UintClass) Check(p *Parser) (*Cursor, bool) {
+	n, last, ok := c.scan(p, false, false)
+	if !ok {
+		return last, false
+	}
+	v, err := c.value(n)
+	if err != nil {
+		return last, false
+	}
+	if c.Key != nil {
+		p.SetValue(c.Key, v)
+	}
+	return last, true
+}
+
+// Parse returns the value of the given unsigned integer, or an error if it
+// does not match the class.
+func (c UintClass) Parse(s string) (uint64, error) {
+	n, err := parseNumber(s, func(p *Parser) (number, *Cursor, bool) {
+		return c.scan(p, false, false)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.value(n)
+}
+
+func (c UintClass) value(n number) (uint64, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(n.literal, "+"), n.base, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < c.Min || c.Max < v {
+		return 0, fmt.Errorf("%d is not in the range [%d, %d]", v, c.Min, c.Max)
+	}
+	return v, nil
+}
+
+func (c UintClass) String() string {
+	return fmt.Sprintf("unsigned integer [%d, %d]", c.Min, c.Max)
+}
+
+// CheckFloat returns a class that checks whether the following runes are a
+// (base 10) floating-point number within the given range (inclusive), e.g.
+// -1.5e3. Both the fraction and the exponent are optional.
+func CheckFloat(min, max float64) FloatClass {
+	return FloatClass{
+		Min: min,
+		Max: max,
+	}
+}
+
+// CheckJSONNumber returns a class that checks whether the following runes are
+// a number as defined by JSON (RFC 8259), e.g. -0.5e+10. Leading zeros and
+// plus signs are not allowed.
+func CheckJSONNumber() FloatClass {
+	return CheckFloat(-math.MaxFloat64, math.MaxFloat64)
+}
+
+// FloatClass is a class that matches a floating-point number, see CheckFloat
+// and CheckJSONNumber. The bases of the NumberFormat are ignored.
+type FloatClass struct {
+	NumberFormat
+	// Min and Max are the bounds of the value (inclusive). Numbers that
+	// overflow a float64 never match.
+	Min, Max float64
+	// Key, if not nil, is used to store the value of the number as a float64
+	// in the parser (see Parser.SetValue).
+	Key interface{}
+}
+
+// Check checks whether the following runes are a number within the range. It
+// consumes all the digits it possibly can.
+func (c FloatClass) Check(p *Parser) (*Cursor, bool) {
+	n, last, ok := c.scan(p, true, true)
+	if !ok {
+		return last, false
+	}
+	v, err := c.value(n)
+	if err != nil {
+		return last, false
+	}
+	if c.Key != nil {
+		p.SetValue(c.Key, v)
+	}
+	return last, true
+}
+
+// Parse returns the value of the given number, or an error if it does not
+// match the class.
+func (c FloatClass) Parse(s string) (float64, error) {
+	n, err := parseNumber(s, func(p *Parser) (number, *Cursor, bool) {
+		return c.scan(p, true, true)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.value(n)
+}
+
+func (c FloatClass) value(n number) (float64, error) {
+	v, err := strconv.ParseFloat(n.literal, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < c.Min || c.Max < v {
+		return 0, fmt.Errorf("%g is not in the range [%g, %g]", v, c.Min, c.Max)
+	}
+	return v, nil
+}
+
+func (c FloatClass) String() string {
+	return fmt.Sprintf("number [%g, %g]", c.Min, c.Max)
+}
@@ -0,0 +1,98 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"math"
+	"testing"
+)
+
+func ExampleCheckInt() {
+	i8 := parser.CheckInt(-128, 127)
+	i8.Bases = []int{2, 10, 16}
+	i8.Separator = '_'
+	for _, s := range []string{"-128", "0x7F", "0b1000_0000", "-0x80", "1_0_0", "007", "99999999999999999999"} {
+		fmt.Println(i8.Parse(s))
+	}
+	// Output:
+	// -128 <nil>
+	// 127 <nil>
+	// 0 128 is not in the range [-128, 127]
+	// -128 <nil>
+	// 100 <nil>
+	// 0 invalid number "007"
+	// 0 strconv.ParseInt: parsing "99999999999999999999": value out of range
+}
+
+func ExampleCheckUint() {
+	p, _ := parser.New([]byte("18446744073709551615 18446744073709551616"))
+	u64 := parser.CheckUint(0, math.MaxUint64)
+	u64.Key = "value"
+	fmt.Println(p.Expect(u64))
+	fmt.Println(p.Value("value"))
+	fmt.Println(p.Check(op.And{' ', u64}))
+	// Output:
+	// U+0035: 5 <nil>
+	// 18446744073709551615
+	// <nil> false
+}
+
+func ExampleCheckFloat() {
+	number := parser.CheckFloat(0, 10000)
+	p, _ := ast.New([]byte("2.5e3"))
+	node, _ := p.Expect(ast.Capture{
+		TypeStrings: []string{"Number"},
+		Value:       number,
+		Action: func(match string, _ []*ast.Node) (interface{}, error) {
+			return number.Parse(match)
+		},
+	})
+	fmt.Println(node, node.Result)
+
+	// The dot and exponent are not part of the number if no digits follow.
+	p0, _ := parser.New([]byte("1.e5"))
+	start := p0.Mark()
+	end, _ := p0.Expect(number)
+	fmt.Println(p0.Slice(start, end))
+	// Output:
+	// ["Number","2.5e3"] 2500
+	// 1
+}
+
+func ExampleCheckJSONNumber() {
+	number := parser.CheckJSONNumber()
+	for _, s := range []string{"-0.5e+10", "01", "+1", ".5", "1e400"} {
+		fmt.Println(number.Parse(s))
+	}
+	// Output:
+	// -5e+09 <nil>
+	// 0 invalid number "01"
+	// 0 invalid number "+1"
+	// 0 invalid number ".5"
+	// 0 strconv.ParseFloat: parsing "1e400": value out of range
+}
+
+// TestCheckJSONNumber compares the class with the JSON decoder.
+func TestCheckJSONNumber(t *testing.T) {
+	number := parser.CheckJSONNumber()
+	for _, s := range []string{
+		"0", "-0", "1", "-12", "0.5", "1.25", "1e3", "1E+3", "1e-3", "-0.0e0",
+		"00", "01", "-", "+1", ".5", "1.", "1.e3", "1e", "1e+", "0x10", "1_0",
+		"--1", "1.5.5",
+	} {
+		_, err := number.Parse(s)
+		if valid := json.Valid([]byte(s)); valid != (err == nil) {
+			t.Errorf("%q: expected valid to be %v, got %v", s, valid, err)
+		}
+	}
+}
+
+func TestCheckIntegerRange_overflow(t *testing.T) {
+	p, _ := parser.New([]byte("18446744073709551628"))
+	if _, ok := p.Check(parser.CheckIntegerRange(0, 20, false)); ok {
+		t.Error("expected overflow to be out of range")
+	}
+}
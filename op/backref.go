@@ -0,0 +1,19 @@
+package op
+
+// Named captures the text matched by the Value under the given name, so it can
+// be matched again with a BackRef. e.g. the terminator of a heredoc.
+//
+// The captured text is stored as a value of the parser, with the BackRef as
+// key. Captures get discarded if the parser backtracks to before they were
+// made. If captures with the same name are nested, the last one that completed
+// wins. So the outer capture replaces the inner one once it completes.
+type Named struct {
+	Name  string
+	Value interface{}
+}
+
+// BackRef matches the text that was captured by the last Named value with the
+// same name.
+type BackRef struct {
+	Name string
+}
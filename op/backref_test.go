@@ -0,0 +1,65 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleBackRef() {
+	// Lua long strings, e.g. [==[ ... ]==].
+	var (
+		open  = op.And{'[', op.Named{Name: "level", Value: op.MinZero('=')}, '['}
+		close = op.And{']', op.BackRef{Name: "level"}, ']'}
+		str   = op.And{open, op.MinZero(op.And{op.Not{Value: close}, parser.CheckRuneFunc(func(r rune) bool {
+			return r != parser.EOD
+		})}), close}
+	)
+
+	for _, s := range []string{"[[]]", "[==[a]]b]=]c]==]", "[=[a]==]"} {
+		p, _ := parser.New([]byte(s))
+		_, err := p.Expect(op.And{str, parser.EOD})
+		fmt.Println(s, err == nil)
+	}
+	// Output:
+	// [[]] true
+	// [==[a]]b]=]c]==] true
+	// [=[a]==] false
+}
+
+func ExampleNamed() {
+	p, _ := parser.New([]byte("aaaa"))
+
+	// Nested captures: the outer capture replaces the inner one once it
+	// completes.
+	_, err := p.Expect(op.And{
+		op.Named{Name: "x", Value: op.And{op.Named{Name: "x", Value: 'a'}, op.BackRef{Name: "x"}}},
+		op.BackRef{Name: "x"}, parser.EOD,
+	})
+	fmt.Println(err)
+	fmt.Println(p.Value(op.BackRef{Name: "x"}))
+
+	// Captures of alternatives that fail get discarded.
+	p, _ = parser.New([]byte("abba"))
+	_, err = p.Expect(op.And{
+		op.Or{
+			op.And{op.Named{Name: "x", Value: "ab"}, 'c'},
+			op.Named{Name: "x", Value: 'a'},
+		},
+		"bb", op.BackRef{Name: "x"}, parser.EOD,
+	})
+	fmt.Println(err)
+
+	// The capture starts after the skipped values.
+	p, _ = parser.New([]byte("  ab ab"))
+	p.SetSkipper(' ')
+	_, err = p.Expect(op.And{op.Named{Name: "x", Value: "ab"}, op.BackRef{Name: "x"}})
+	fmt.Println(err)
+	fmt.Printf("%q\n", p.Value(op.BackRef{Name: "x"}))
+	// Output:
+	// <nil>
+	// aa
+	// <nil>
+	// <nil>
+	// "ab"
+}
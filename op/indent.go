@@ -0,0 +1,16 @@
+package op
+
+// Indent consumes the indentation at the start of a line, which should be
+// deeper than the current indentation level. The indentation of the line
+// becomes the new indentation level.
+type Indent struct{}
+
+// Dedent closes the current indentation level. The indentation of the line
+// should be less deep than the current level. It does not consume any data, so
+// it can be repeated to close multiple levels at once. The end of the data
+// closes all the levels.
+type Dedent struct{}
+
+// SameIndent consumes the indentation at the start of a line, which should be
+// equal to the current indentation level.
+type SameIndent struct{}
@@ -0,0 +1,72 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleIndent() {
+	p, _ := parser.New([]byte("a\n  b\n  c"))
+
+	_, err := p.Expect(op.And{'a', '\n', op.Indent{}, 'b', '\n'})
+	fmt.Println(err)
+	fmt.Println(p.Mark().Indentation())
+	fmt.Println(p.Expect(op.Indent{})) // Same level.
+	fmt.Println(p.Expect(op.And{op.SameIndent{}, 'c'}))
+	// Output:
+	// <nil>
+	// 2
+	// <nil> parse conflict [02:002]: expected op.Indent INDENT but got "  c"
+	// U+0063: c <nil>
+}
+
+func ExampleDedent() {
+	p, _ := parser.New([]byte("a\n  b\n    c\nd"))
+	start := p.Mark()
+
+	// Both levels get closed by the last line.
+	fmt.Println(p.Expect(op.And{
+		'a', '\n', op.Indent{}, 'b', '\n', op.Indent{}, 'c', '\n',
+		op.Dedent{}, op.Dedent{}, op.SameIndent{}, 'd',
+	}))
+	fmt.Println(p.Mark().Indentation())
+
+	// Jumping back also restores the indentation levels.
+	p.Jump(start)
+	fmt.Println(p.Expect(op.And{'a', '\n', op.Indent{}, 'b', '\n', op.Indent{}}))
+	fmt.Println(p.Mark().Indentation())
+	p.Jump(start)
+	fmt.Println(p.Mark().Indentation())
+	// Output:
+	// U+0064: d <nil>
+	// 0
+	// U+0020:   <nil>
+	// 4
+	// 0
+}
+
+func ExampleDedent_inconsistent() {
+	p, _ := parser.New([]byte("a\n    b\n  c"))
+
+	_, _ = p.Expect(op.And{'a', '\n', op.Indent{}, 'b', '\n'})
+	fmt.Println(p.Expect(op.Dedent{}))
+	// Output:
+	// <nil> indentation error [02:002]: unindent to 2 does not match any outer indentation level
+}
+
+func ExampleSameIndent() {
+	p, _ := parser.New([]byte("a\n\tb\n        c"))
+	_, _ = p.Expect(op.And{'a', '\n', op.Indent{}, 'b', '\n'})
+
+	// A tab is equal to 8 spaces by default.
+	fmt.Println(p.Expect(op.And{op.SameIndent{}, 'c'}))
+
+	p, _ = parser.New([]byte("a\n\tb"))
+	p.SetTabWidth(0) // Disallow tabs.
+	_, _ = p.Expect(op.And{'a', '\n'})
+	fmt.Println(p.Expect(op.Indent{}))
+	// Output:
+	// U+0063: c <nil>
+	// <nil> indentation error [01:000]: tab in indentation
+}
@@ -22,3 +22,27 @@ type Or []interface{}
 // XOr represents a sequence of exclusive alternative values. Only one of the
 // values van be valid. It can contain only one valid match.
 type XOr []interface{}
+
+// Cut (~) commits to the current alternative. If a value that follows the cut
+// within an And fails, then the nearest enclosing Or does not try its other
+// alternatives, but fails with the error of that value. The values that enclose
+// that Or are not affected by the cut, e.g. they can try their alternatives.
+type Cut struct{}
+
+// Expect labels the failure of the Value. If the Value fails, then the parser
+// does not backtrack into other alternatives, but returns an error with the
+// label. e.g. Expect{Value: ')', Label: "missing closing parenthesis"}.
+//
+// The error is a *parser.ExpectedParseError of which the Label is set, all the
+// enclosing values return it as is.
+type Expect struct {
+	Value interface{}
+	Label string
+}
+
+// Token marks the Value as a lexical unit. The skipper of the parser gets
+// applied before the Value, but not within it. e.g. Token{Value: integer}
+// skips the whitespace before the integer, not in between its digits.
+type Token struct {
+	Value interface{}
+}
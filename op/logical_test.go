@@ -67,3 +67,84 @@ func ExampleXOr() {
 	// parse conflict [00:001]: expected op.XOr xor['d' "da" "data"] but got "da"
 	// parse conflict [00:000]: expected op.XOr xor['a' 't'] but got 'd'
 }
+
+func ExampleCut() {
+	statement := op.Or{
+		op.And{"if", op.Cut{}, ' ', '(', 'x', ')'},
+		"ifx", // Never tried once "if" matched.
+	}
+
+	p, _ := parser.New([]byte("if (x)"))
+	fmt.Println(p.Expect(statement))
+	p, _ = parser.New([]byte("ifx"))
+	fmt.Println(p.Expect(statement))
+	// Output:
+	// U+0029: ) <nil>
+	// <nil> parse conflict [00:002]: expected int32 ' ' but got 'x'
+}
+
+func ExampleCut_nested() {
+	statement := op.And{"if", op.Cut{}, '('}
+
+	// The cut only affects the op.Or that directly contains it.
+	p, _ := parser.New([]byte("ify"))
+	fmt.Println(p.Expect(op.Or{op.Or{statement, "iff"}, "ify"}))
+	// Within a predicate it is a regular failure of the value.
+	p, _ = parser.New([]byte("ifx"))
+	fmt.Println(p.Expect(op.Not{Value: statement}))
+	// Without an enclosing op.Or it is a regular failure as well.
+	p, _ = parser.New([]byte("ifx"))
+	fmt.Println(p.Expect(statement))
+	// Output:
+	// U+0079: y <nil>
+	// <nil> <nil>
+	// <nil> parse conflict [00:002]: expected int32 '(' but got 'x'
+}
+
+func ExampleExpect_not() {
+	group := op.And{'(', 'x', op.Expect{Value: ')', Label: "missing closing parenthesis"}}
+
+	p, _ := parser.New([]byte("(x)"))
+	fmt.Println(p.Expect(op.Not{Value: group}))
+	p, _ = parser.New([]byte("(y)"))
+	fmt.Println(p.Expect(op.Not{Value: group}))
+	// Labeled failures are not a mismatch of the value.
+	p, _ = parser.New([]byte("(x]"))
+	fmt.Println(p.Expect(op.Not{Value: group}))
+	// Output:
+	// <nil> parse conflict [00:002]: expected op.Not !and['(' 'x' ')'] but got "(x)"
+	// <nil> <nil>
+	// <nil> parse conflict [00:002]: missing closing parenthesis: expected int32 ')' but got ']'
+}
+
+func ExampleExpect() {
+	group := op.And{'(', 'x', op.Expect{Value: ')', Label: "missing closing parenthesis"}}
+
+	p, _ := parser.New([]byte("(x"))
+	fmt.Println(p.Expect(op.Or{group, "(x"}))
+
+	// Recover by ignoring the missing parenthesis.
+	p, _ = parser.New([]byte("(x"))
+	p.SetRecovery("missing closing parenthesis", op.Ensure{Value: parser.EOD})
+	_, err := p.Expect(group)
+	fmt.Println(err)
+	fmt.Println(p.Recovered())
+	// Output:
+	// <nil> parse conflict [00:002]: missing closing parenthesis: expected int32 ')' but got ""
+	// <nil>
+	// [parse conflict [00:002]: missing closing parenthesis: expected int32 ')' but got ""]
+}
+
+func ExampleExpect_label() {
+	group := op.And{'(', 'x', op.Expect{Value: ')', Label: "missing closing parenthesis"}}
+
+	p, _ := parser.New([]byte("(x]"))
+	_, err := p.Expect(op.Or{op.MinOne(group), 'x'})
+	if err, ok := err.(*parser.ExpectedParseError); ok {
+		fmt.Println(err.Label)
+		fmt.Println(parser.Stringer(err.Expected), err.String)
+	}
+	// Output:
+	// missing closing parenthesis
+	// ')' ]
+}
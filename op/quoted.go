@@ -0,0 +1,45 @@
+package op
+
+// Quoted matches a string literal that is enclosed in quotes, e.g. "a\tb". The
+// string gets decoded while it is matched: the quotes are removed and the
+// escape sequences are replaced by their values. An invalid escape sequence
+// results in an error that does not backtrack into other alternatives.
+type Quoted struct {
+	// Quotes contains the delimiters that can open the string, the string
+	// needs to be closed by the same delimiter. e.g. `"` or `"""`. Quotes are
+	// tried in order, defaults to `"`.
+	Quotes []string
+	// Escape is the rune that starts an escape sequence, e.g. '\\'. Strings
+	// without escape sequences (raw strings) have no escape rune.
+	Escape rune
+	// Escapes maps the runes that are allowed after the escape rune to the
+	// runes they represent, e.g. 'n' to '\n'. Escaping the quotes or the
+	// escape rune itself also requires an entry, e.g. '"' to '"'.
+	Escapes map[rune]rune
+	// Unicode allows escape sequences of four hexadecimal digits, e.g. \u00E9.
+	// UTF-16 surrogate pairs (e.g. \uD83D\uDE00) are combined into one rune.
+	Unicode bool
+	// Multiline allows line breaks within the string.
+	Multiline bool
+	// Key, if not nil, is used to store the decoded string in the parser.
+	Key interface{}
+}
+
+// JSONString matches a JSON (RFC 8259) string. Unlike JSON, control characters
+// other than line breaks are not rejected.
+var JSONString = Quoted{
+	Quotes: []string{`"`},
+	Escape: '\\',
+	Escapes: map[rune]rune{
+		'"': '"', '\\': '\\', '/': '/',
+		'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t',
+	},
+	Unicode: true,
+}
+
+// RawString matches a raw string in backquotes, like the raw string literals of
+// Go. It can span multiple lines and does not contain escape sequences.
+var RawString = Quoted{
+	Quotes:    []string{"`"},
+	Multiline: true,
+}
@@ -0,0 +1,89 @@
+package op_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleQuoted() {
+	str := op.JSONString
+	str.Key = "string"
+
+	p, _ := parser.New([]byte(`"tab\t\"quote\" \u00e9 \uD83D\uDE00"`))
+	_, err := p.Expect(op.And{str, parser.EOD})
+	fmt.Println(err)
+	fmt.Printf("%q\n", p.Value("string"))
+	// Output:
+	// <nil>
+	// "tab\t\"quote\" é 😀"
+}
+
+func ExampleQuoted_errors() {
+	for _, s := range []string{`"\q"`, `"\u00G0"`, `"\uD83D"`, `"\uDE00\uD83D"`, `"a`} {
+		_, err := parser.Unquote(op.JSONString, s)
+		fmt.Println(err)
+	}
+	// Output:
+	// parse conflict [00:002]: invalid escape sequence: expected parser.CharSet ["/bfnrtux5C] but got 'q'
+	// parse conflict [00:005]: invalid unicode escape: expected parser.CharSet [0-9A-Fa-f] but got 'G'
+	// parse conflict [00:001]: unpaired surrogate: expected parser.CharSet [xDC00-xDFFF] but got "\\uD83D"
+	// parse conflict [00:001]: unpaired surrogate: expected parser.CharSet [xD800-xDBFF] but got "\\uDE00"
+	// parse conflict [00:002]: expected string "\"" but got ""
+}
+
+func ExampleQuoted_raw() {
+	str := op.Quoted{Quotes: []string{`"""`, `"`}, Escape: '\\', Escapes: map[rune]rune{'n': '\n'}, Multiline: true}
+	for _, s := range []string{`"a\nb"`, "\"\"\"a\n\"b\"c\"\"\"", "`a\\nb`"} {
+		fmt.Println(parser.Unquote(str, s))
+	}
+	fmt.Println(parser.Unquote(op.RawString, "`a\\nb`"))
+	// Output:
+	// a
+	// b <nil>
+	// a
+	// "b"c <nil>
+	//  parse conflict [00:000]: expected op.Quoted """...""" "..." but got '`'
+	// a\nb <nil>
+}
+
+func ExampleQuoted_or() {
+	// Invalid escape sequences do not backtrack into other alternatives.
+	p, _ := parser.New([]byte(`"\x"`))
+	_, err := p.Expect(op.Or{op.JSONString, op.And{'"', op.MinZero(parser.CheckRuneFunc(func(r rune) bool {
+		return r != '"' && r != parser.EOD
+	})), '"'}})
+	fmt.Println(err)
+	fmt.Println(err.(*parser.ExpectedParseError).Label)
+	// Output:
+	// parse conflict [00:002]: invalid escape sequence: expected parser.CharSet ["/bfnrtux5C] but got 'x'
+	// invalid escape sequence
+}
+
+// TestQuoted compares op.JSONString with the JSON decoder.
+func TestQuoted(t *testing.T) {
+	for _, s := range []string{
+		`""`, `"a"`, `"\""`, `"\\"`, `"\/"`, `"\b\f\n\r\t"`, `"Aé"`,
+		`"\u00e9"`, `"\uD834\uDD1E"`, `"\ud834\udd1e"`, `"é😀"`,
+		`"`, `"\"`, `"\a"`, `"\u12"`, `"a"b`, "\"a\nb\"",
+	} {
+		var expected string
+		jsonErr := json.Unmarshal([]byte(s), &expected)
+		actual, err := parser.Unquote(op.JSONString, s)
+		if (jsonErr == nil) != (err == nil) {
+			t.Errorf("%s: expected error %v, got %v", s, jsonErr, err)
+			continue
+		}
+		if err == nil && actual != expected {
+			t.Errorf("%s: expected %q, got %q", s, expected, actual)
+		}
+	}
+	// The JSON decoder replaces unpaired surrogates with U+FFFD.
+	for _, s := range []string{`"\uD834"`, `"\uD834A"`, `"\uD834\u0041"`, `"\uDD1E"`} {
+		if _, err := parser.Unquote(op.JSONString, s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
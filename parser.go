@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"fmt"
 	"github.com/di-wu/parser/op"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -13,6 +15,17 @@ type Parser struct {
 	buffer []byte
 	cursor *Cursor
 	decode func([]byte) (rune, int)
+	// tabWidth is the number of columns a tab advances the indentation to.
+	tabWidth int
+	// depth is the number of nested Expect calls, a cut does not affect the
+	// values outside of the outermost call.
+	depth int
+	// recovery contains the recovery values of the labels of op.Expect.
+	recovery map[string]interface{}
+	// skipper gets skipped before tokens, if not within a lexical value (e.g.
+	// an op.Token or class). lexical keeps track of the depth of the latter.
+	skipper interface{}
+	lexical int
 
 	converter func(interface{}) interface{}
 	operator  func(interface{}) (*Cursor, error)
@@ -21,8 +34,9 @@ type Parser struct {
 // New creates a new Parser.
 func New(input []byte) (*Parser, error) {
 	p := Parser{
-		buffer: input,
-		decode: utf8.DecodeRune,
+		buffer:   input,
+		decode:   utf8.DecodeRune,
+		tabWidth: 8,
 	}
 
 	current, size := p.decode(p.buffer)
@@ -173,10 +187,28 @@ func (p *Parser) Slice(start *Cursor, end *Cursor) string {
 //	- rune & string
 //	- func(p *Parser) (*Cursor, bool)
 //	  (== AnonymousClass)
+//	- CharSet, UnicodeClass & *unicode.RangeTable
 //	- []interface{}
 //	  (== op.And)
 //	- operators: op.Not, op.And, op.Or & op.XOr
+//	- indentation: op.Indent, op.Dedent & op.SameIndent
+//	- back references: op.Named & op.BackRef
+//	- string literals: op.Quoted
+//	- op.Cut, op.Expect & op.Token
 func (p *Parser) Expect(i interface{}) (*Cursor, error) {
+	p.depth++
+	mark, err := p.expect(i)
+	if p.depth--; p.depth == 0 {
+		if e, ok := err.(*CutError); ok {
+			// A cut without an enclosing op.Or.
+			return mark, e.Err
+		}
+	}
+	return mark, err
+}
+
+// expect checks whether the buffer contains the given value, see Expect.
+func (p *Parser) expect(i interface{}) (*Cursor, error) {
 	state := state{p: p}
 
 	i = ConvertAliases(i)
@@ -185,6 +217,10 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		i = p.converter(i)
 	}
 
+	if p.skipper != nil && p.lexical == 0 && isToken(i) {
+		return p.expectToken(i)
+	}
+
 	if p.operator != nil {
 		// Takes priority over default values. If an unsupported error is
 		// returned we can check if one of the predefined types match.
@@ -213,7 +249,41 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		}
 
 	case AnonymousClass:
+		p.lexical++
 		last, passed := v(p)
+		p.lexical--
+		if !passed {
+			if last == nil {
+				last = start
+			}
+			return nil, p.ExpectedParseError(v, start, p.Jump(last).Peek())
+		}
+		state.Ok(last)
+	case CharSet:
+		if !v.Contains(p.cursor.Rune) {
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		state.Ok(p.Mark())
+	case UnicodeClass:
+		if v.Table == nil {
+			return nil, &ExpectError{
+				Message: fmt.Sprintf("unknown unicode class %s", v.Name),
+			}
+		}
+		if !unicode.Is(v.Table, p.cursor.Rune) {
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		state.Ok(p.Mark())
+	case *unicode.RangeTable:
+		if !unicode.Is(v, p.cursor.Rune) {
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		state.Ok(p.Mark())
+	case Class:
+		// Classes that can describe themselves, see ConvertAliases.
+		p.lexical++
+		last, passed := v.Check(p)
+		p.lexical--
 		if !passed {
 			if last == nil {
 				last = start
@@ -222,21 +292,123 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		}
 		state.Ok(last)
 
+	case op.Indent, op.Dedent, op.SameIndent:
+		last, err := p.expectIndent(v)
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Named:
+		if p.skipper != nil && p.lexical == 0 {
+			// The capture starts after the skipped values.
+			p.lexical++
+			_, _ = p.Expect(op.MinZero(p.skipper))
+			p.lexical--
+		}
+		from := p.Mark()
+		last, err := p.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		var captured string
+		if last != nil {
+			captured = p.Slice(from, last)
+		} else {
+			// Nothing matched, the skipped values belong to the next token.
+			p.Jump(start)
+		}
+		state.Ok(last)
+		p.SetValue(op.BackRef{Name: v.Name}, captured)
+	case op.BackRef:
+		captured, ok := p.Value(v).(string)
+		if !ok {
+			// Nothing captured (yet).
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		if captured == "" {
+			break
+		}
+		last, err := p.Expect(captured)
+		if err != nil {
+			if err, ok := err.(*ExpectedParseError); ok {
+				return nil, p.ExpectedParseError(v, start, &err.Conflict)
+			}
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Quoted:
+		p.lexical++
+		last, decoded, err := p.expectQuoted(v)
+		p.lexical--
+		if err != nil {
+			return nil, err
+		}
+		if v.Key != nil {
+			p.SetValue(v.Key, decoded)
+		}
+		state.Ok(last)
+
+	case op.Cut:
+		// Only has meaning within an op.And.
+	case op.Token:
+		p.lexical++
+		last, err := p.Expect(v.Value)
+		p.lexical--
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+	case op.Expect:
+		last, err := p.Expect(v.Value)
+		if err != nil {
+			if last, err = p.expectLabeled(v, start, err); err != nil {
+				return nil, err
+			}
+		}
+		state.Ok(last)
+
 	case op.Not:
 		defer p.Jump(start)
-		if last, err := p.Expect(v.Value); err == nil {
+		last, err := p.Expect(v.Value)
+		if err == nil {
 			return nil, p.ExpectedParseError(v, start, last)
 		}
+		// A labeled failure is an error in the data (e.g. a missing closing
+		// parenthesis) rather than a mismatch, the lookahead does not turn it
+		// into a match.
+		if isLabeled(err) {
+			return nil, err
+		}
 	case op.Ensure:
 		if last, err := p.Expect(v.Value); err != nil {
+			if err, ok := err.(*CutError); ok {
+				return last, err.Err
+			}
 			return last, err
 		}
 		p.Jump(start)
 	case op.And:
-		var last *Cursor
+		var (
+			last *Cursor
+			cut  bool
+		)
 		for _, i := range v {
+			if _, ok := i.(op.Cut); ok {
+				cut = true
+			}
 			mark, err := p.Expect(i)
 			if err != nil {
+				if Committed(err) {
+					p.Jump(start)
+					return nil, err
+				}
+				if cut {
+					p.Jump(start)
+					return nil, &CutError{Err: err}
+				}
 				if last == nil {
 					last = start
 				}
@@ -253,27 +425,46 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 				last = mark
 				break
 			}
+			if err, ok := err.(*CutError); ok {
+				// Committed to this alternative, the cut does not affect the
+				// enclosing values.
+				p.Jump(start)
+				return nil, err.Err
+			}
+			if isLabeled(err) {
+				return nil, err
+			}
 		}
 		if last == nil {
 			return nil, p.ExpectedParseError(v, start, start)
 		}
 		state.Ok(last)
 	case op.XOr:
-		var last *Cursor
+		var last, end *Cursor
 		for _, i := range v {
 			mark, err := p.Expect(i)
+			if err, ok := err.(*CutError); ok {
+				p.Jump(start)
+				return nil, err.Err
+			}
+			if isLabeled(err) {
+				p.Jump(start)
+				return nil, err
+			}
 			if err == nil {
 				if last != nil {
 					p.Jump(start)
 					return nil, p.ExpectedParseError(v, start, mark)
 				}
-				last = mark
+				last, end = mark, p.Mark()
 				p.Jump(start) // Go back to the start.
 			}
 		}
 		if last == nil {
 			return nil, p.ExpectedParseError(v, start, last)
 		}
+		// Restores the state of the match.
+		p.Jump(end)
 		state.Ok(last)
 
 	case op.Range:
@@ -284,6 +475,10 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		for {
 			mark, err := p.Expect(v.Value)
 			if err != nil {
+				if Committed(err) {
+					p.Jump(start)
+					return nil, err
+				}
 				break
 			}
 			last = mark
@@ -323,6 +518,7 @@ func (p *Parser) Check(i interface{}) (*Cursor, bool) {
 //
 // - (int, rune)
 // - ([]interface{}, op.And)
+// - (Class, AnonymousClass), unless the class implements fmt.Stringer
 func ConvertAliases(i interface{}) interface{} {
 	switch v := i.(type) {
 	case int:
@@ -330,7 +526,14 @@ func ConvertAliases(i interface{}) interface{} {
 
 	case func(p *Parser) (*Cursor, bool):
 		return AnonymousClass(v)
+	case CharSet, UnicodeClass:
+		// Keeps its String method.
+		return v
 	case Class:
+		if _, ok := v.(fmt.Stringer); ok {
+			// Keeps its String method, e.g. CharSet and RuneClass.
+			return v
+		}
 		return AnonymousClass(v.Check)
 
 	case []interface{}:
@@ -262,3 +262,78 @@ func TestParser_Expect_class_err(t *testing.T) {
 		t.Error(expected.String)
 	}
 }
+
+func ExampleParser_SetValue() {
+	p, _ := parser.New([]byte("<<END\nsome text\nEND"))
+
+	type terminator struct{}
+	// Stores the identifier as the terminator of the heredoc.
+	open := func(p *parser.Parser) (*parser.Cursor, bool) {
+		start := p.Mark()
+		last, ok := p.Check(op.MinOne(parser.CheckRuneRange('A', 'Z')))
+		if !ok {
+			return nil, false
+		}
+		p.SetValue(terminator{}, p.Slice(start, last))
+		return last, true
+	}
+	// Checks whether the line is equal to the terminator.
+	end := func(p *parser.Parser) (*parser.Cursor, bool) {
+		s, _ := p.Value(terminator{}).(string)
+		if s == "" {
+			return nil, false
+		}
+		return p.Check(s)
+	}
+
+	line := op.MinZero(parser.CheckRuneFunc(func(r rune) bool {
+		return r != '\n'
+	}))
+
+	fmt.Println(p.Expect(op.And{
+		"<<", open, '\n',
+		op.MinZero(op.And{op.Not{Value: end}, line, '\n'}),
+		end,
+	}))
+	fmt.Println(p.Value(terminator{}))
+	// Output:
+	// U+0044: D <nil>
+	// END
+}
+
+func ExampleParser_SetValue_backtrack() {
+	p, _ := parser.New([]byte("ab"))
+	set := func(value string) parser.AnonymousClass {
+		return func(p *parser.Parser) (*parser.Cursor, bool) {
+			p.SetValue("key", value)
+			return nil, true
+		}
+	}
+
+	// The value of the first alternative gets discarded, it did not match.
+	_, _ = p.Expect(op.Or{
+		op.And{set("first"), 'a', 'c'},
+		op.And{'a', set("second"), 'b'},
+	})
+	fmt.Println(p.Value("key"))
+	// Output:
+	// second
+}
+
+func ExampleParser_SetSkipper() {
+	p, _ := parser.New([]byte("let  x =\n\t42 ;"))
+	p.SetSkipper(op.Or{' ', '\t', '\n'})
+
+	integer := op.Token{Value: op.MinOne(parser.CheckRuneRange('0', '9'))}
+	_, err := p.Expect(op.And{"let", 'x', '=', integer, ';', parser.EOD})
+	fmt.Println(err)
+
+	// No spaces within tokens.
+	p, _ = parser.New([]byte("4 2;"))
+	p.SetSkipper(' ')
+	_, err = p.Expect(op.And{integer, ';'})
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// parse conflict [00:001]: expected op.And and['0'-'9'+ ';'] but got "4 "
+}
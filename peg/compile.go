@@ -0,0 +1,196 @@
+package peg
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"strconv"
+)
+
+// Compile parses the given PEG grammar and converts it to a grammar that can be
+// used to parse data directly. Every definition produces a node.
+func Compile(data []byte) (*grammar.Grammar, error) {
+	n, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return CompileNode(n, data)
+}
+
+// CompileNode converts the given (parsed) PEG grammar to a grammar. The data is
+// used to report the positions of errors.
+func CompileNode(n *ast.Node, data []byte) (*grammar.Grammar, error) {
+	var (
+		g       = grammar.New()
+		defined = make(map[string]bool)
+	)
+	for _, def := range n.Children() {
+		if defined[def.FirstChild.Value] {
+			return nil, grammar.NewImportError("peg", data, def.Start, "rule %s is already defined", def.FirstChild.Value)
+		}
+		defined[def.FirstChild.Value] = true
+	}
+	c := compiler{data: data, defined: defined}
+	for _, def := range n.Children() {
+		value, err := c.compile(def.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		_ = g.Add(&grammar.Rule{
+			Name:    def.FirstChild.Value,
+			Capture: true,
+			Value:   value,
+		})
+	}
+	return g, nil
+}
+
+type compiler struct {
+	data []byte
+	// defined contains the names of all the rules defined in the grammar.
+	defined map[string]bool
+}
+
+// error returns an error at the start of the given node.
+func (c compiler) error(n *ast.Node, format string, a ...interface{}) error {
+	return grammar.NewImportError("peg", c.data, n.Start, format, a...)
+}
+
+func (c compiler) compile(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case ExpressionType, SequenceType:
+		var values []interface{}
+		for _, child := range n.Children() {
+			v, err := c.compile(child)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == ExpressionType {
+			return op.Or(values), nil
+		}
+		return op.And(values), nil
+	case PosLookType, NegLookType:
+		v, err := c.compile(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		if n.Type == PosLookType {
+			return op.Ensure{Value: v}, nil
+		}
+		return op.Not{Value: v}, nil
+	case SuffixType:
+		v, err := c.compile(n.FirstChild)
+		if err != nil || n.FirstChild == n.LastChild {
+			return v, err
+		}
+		switch n.LastChild.Value {
+		case "?":
+			return op.Optional(v), nil
+		case "*":
+			return op.MinZero(v), nil
+		default:
+			return op.MinOne(v), nil
+		}
+	case IdentifierType:
+		if !c.defined[n.Value] {
+			return nil, c.error(n, "undefined rule %s", n.Value)
+		}
+		return grammar.Ref{Name: n.Value}, nil
+	case LiteralType:
+		s, err := c.unescape(n)
+		if err != nil {
+			return nil, err
+		}
+		if r := []rune(s); len(r) == 1 {
+			return r[0], nil
+		}
+		return s, nil
+	case ClassType:
+		var or op.Or
+		for _, child := range n.Children() {
+			v, err := c.compile(child)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, v)
+		}
+		if len(or) == 1 {
+			return or[0], nil
+		}
+		return or, nil
+	case RangeType:
+		min, err := c.compile(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		max, err := c.compile(n.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		if max.(rune) < min.(rune) {
+			return nil, c.error(n, "invalid range %s", c.data[n.Start:n.End])
+		}
+		return parser.CheckRuneRange(min.(rune), max.(rune)), nil
+	case CharType:
+		s, err := c.unescape(n)
+		if err != nil {
+			return nil, err
+		}
+		return []rune(s)[0], nil
+	case DotType:
+		return parser.AnyRune{}, nil
+	default:
+		return nil, c.error(n, "unexpected node %s", n.TypeString())
+	}
+}
+
+// unescape decodes the escape sequences within the value of a Literal or Char.
+func (c compiler) unescape(n *ast.Node) (string, error) {
+	var (
+		runes = []rune(n.Value)
+		s     []rune
+	)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' {
+			s = append(s, runes[i])
+			continue
+		}
+		i++
+		switch r := runes[i]; r {
+		case 'n':
+			s = append(s, '\n')
+		case 'r':
+			s = append(s, '\r')
+		case 't':
+			s = append(s, '\t')
+		default:
+			if r < '0' || '7' < r {
+				s = append(s, r)
+				continue
+			}
+			// Up to three octal digits, only two if the first one is
+			// greater than 2.
+			end := i + 3
+			if '2' < r {
+				end--
+			}
+			j := i + 1
+			for j < len(runes) && j < end && '0' <= runes[j] && runes[j] <= '7' {
+				j++
+			}
+			v, err := strconv.ParseUint(string(runes[i:j]), 8, 8)
+			if err != nil {
+				return "", c.error(n, "invalid escape \\%s", string(runes[i:j]))
+			}
+			s = append(s, rune(v))
+			i = j - 1
+		}
+	}
+	return string(s), nil
+}
@@ -0,0 +1,132 @@
+package peg_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/peg"
+	"testing"
+)
+
+func ExampleCompile() {
+	g, err := peg.Compile([]byte(`
+# Arithmetic expressions.
+Sum     <- Product (SumOp Product)*
+SumOp   <- [+\-]
+Product <- Value ('*' Value)*
+Value   <- [0-9]+ / '(' Sum ')'
+`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(g.Parse("Sum", []byte("1-(2+3)*4")))
+	// Output:
+	// ["Sum",[["Product",[["Value","1"]]],["SumOp","-"],["Product",[["Value",[["Sum",[["Product",[["Value","2"]]],["SumOp","+"],["Product",[["Value","3"]]]]]]],["Value","4"]]]]] <nil>
+}
+
+func ExampleCompile_lookahead() {
+	g, _ := peg.Compile([]byte(`
+Keyword    <- ("if" / "else") !IdentChar
+Identifier <- !Keyword IdentChar+
+IdentChar  <- [a-zA-Z_]
+Comment    <- '/*' (!'*/' .)* '*/'
+`))
+	fmt.Println(g.Parse("Identifier", []byte("iffy")))
+	fmt.Println(g.Parse("Keyword", []byte("if")))
+	_, err := g.Parse("Identifier", []byte("if"))
+	fmt.Println(err != nil)
+	fmt.Println(g.Parse("Comment", []byte("/* \\ */")))
+	// Output:
+	// ["Identifier",[["IdentChar","i"],["IdentChar","f"],["IdentChar","f"],["IdentChar","y"]]] <nil>
+	// ["Keyword","if"] <nil>
+	// true
+	// ["Comment","/* \\ */"] <nil>
+}
+
+func TestCompile_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{
+			grammar: "A <- 'a'\nA <- 'b'\n",
+			err:     "peg [01:000]: rule A is already defined",
+		},
+		{
+			grammar: "A <- 'a' B\n  / C\n",
+			err:     "peg [00:009]: undefined rule B",
+		},
+		{
+			grammar: "A <- [a-zz-a]\n",
+			err:     "peg [00:009]: invalid range z-a",
+		},
+	} {
+		if _, err := peg.Compile([]byte(test.grammar)); err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
+
+// The grammar of PEG itself, from figure 1 of the paper.
+const ford = `# Hierarchical syntax
+Grammar    <- Spacing Definition+ EndOfFile
+Definition <- Identifier LEFTARROW Expression
+Expression <- Sequence (SLASH Sequence)*
+Sequence   <- Prefix*
+Prefix     <- (AND / NOT)? Suffix
+Suffix     <- Primary (QUESTION / STAR / PLUS)?
+Primary    <- Identifier !LEFTARROW
+            / OPEN Expression CLOSE
+            / Literal / Class / DOT
+
+# Lexical syntax
+Identifier <- IdentStart IdentCont* Spacing
+IdentStart <- [a-zA-Z_]
+IdentCont  <- IdentStart / [0-9]
+Literal    <- ['] (!['] Char)* ['] Spacing
+            / ["] (!["] Char)* ["] Spacing
+Class      <- '[' (!']' Range)* ']' Spacing
+Range      <- Char '-' Char / Char
+Char       <- '\\' [nrt'"\[\]\\]
+            / '\\' [0-2][0-7][0-7]
+            / '\\' [0-7][0-7]?
+            / !'\\' .
+
+LEFTARROW  <- '<-' Spacing
+SLASH      <- '/' Spacing
+AND        <- '&' Spacing
+NOT        <- '!' Spacing
+QUESTION   <- '?' Spacing
+STAR       <- '*' Spacing
+PLUS       <- '+' Spacing
+OPEN       <- '(' Spacing
+CLOSE      <- ')' Spacing
+DOT        <- '.' Spacing
+
+Spacing    <- (Space / Comment)*
+Comment    <- '#' (!EndOfLine .)* EndOfLine
+Space      <- ' ' / '\t' / EndOfLine
+EndOfLine  <- '\r\n' / '\n' / '\r'
+EndOfFile  <- !.
+`
+
+// TestCompile_ford checks whether the compiled grammar of PEG is able to parse
+// itself, with the same number of definitions.
+func TestCompile_ford(t *testing.T) {
+	g, err := peg.Compile([]byte(ford))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := g.Parse("Grammar", []byte(ford))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var definitions int
+	for _, c := range n.Children() {
+		if c.TypeString() == "Definition" {
+			definitions++
+		}
+	}
+	if expected := len(g.Rules()); definitions != expected {
+		t.Errorf("expected %d definitions, got %d", expected, definitions)
+	}
+}
@@ -0,0 +1,235 @@
+// Package peg imports grammars written in the PEG notation of Bryan Ford's
+// paper "Parsing Expression Grammars: A Recognition-Based Syntactic Foundation".
+package peg
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// Node Types
+const (
+	Unknown = iota
+
+	// PEG (Ford, 2004)
+	GrammarType    // 001
+	DefinitionType // 002
+	IdentifierType // 003
+	ExpressionType // 004
+	SequenceType   // 005
+	PosLookType    // 006
+	NegLookType    // 007
+	SuffixType     // 008
+	QuantifierType // 009
+	LiteralType    // 010
+	ClassType      // 011
+	RangeType      // 012
+	CharType       // 013
+	DotType        // 014
+)
+
+var NodeTypes = []string{
+	"UNKNOWN",
+
+	// PEG (Ford, 2004)
+	"Grammar",
+	"Definition",
+	"Identifier",
+	"Expression",
+	"Sequence",
+	"PosLook",
+	"NegLook",
+	"Suffix",
+	"Quantifier",
+	"Literal",
+	"Class",
+	"Range",
+	"Char",
+	"Dot",
+}
+
+// Parse parses the given PEG grammar.
+func Parse(data []byte) (*ast.Node, error) {
+	return ast.Parse(data, Grammar)
+}
+
+// Grammar <- Spacing Definition+ EndOfFile
+func Grammar(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        GrammarType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{spacing, op.MinOne(Definition), parser.EOD},
+	})
+}
+
+// Definition <- Identifier LEFTARROW Expression
+func Definition(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        DefinitionType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Identifier, leftArrow, Expression},
+	})
+}
+
+// Identifier <- IdentStart IdentCont* Spacing
+func Identifier(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.And{
+		ast.Capture{
+			Type:        IdentifierType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{identStart, op.MinZero(op.Or{identStart, parser.CheckRuneRange('0', '9')})},
+		},
+		spacing,
+	})
+}
+
+// Expression <- Sequence (SLASH Sequence)*
+func Expression(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        ExpressionType,
+		TypeStrings: NodeTypes,
+		Value:       op.And{Sequence, op.MinZero(op.And{token('/'), Sequence})},
+	})
+}
+
+// Sequence <- Prefix*
+func Sequence(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        SequenceType,
+		TypeStrings: NodeTypes,
+		Value:       op.MinZero(prefix),
+	})
+}
+
+// Prefix <- (AND / NOT)? Suffix
+func prefix(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.Or{
+		ast.Capture{
+			Type:        PosLookType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{token('&'), Suffix},
+		},
+		ast.Capture{
+			Type:        NegLookType,
+			TypeStrings: NodeTypes,
+			Value:       op.And{token('!'), Suffix},
+		},
+		Suffix,
+	})
+}
+
+// Suffix <- Primary (QUESTION / STAR / PLUS)?
+func Suffix(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        SuffixType,
+		TypeStrings: NodeTypes,
+		Value: op.And{
+			primary,
+			op.Optional(op.And{
+				ast.Capture{
+					Type:        QuantifierType,
+					TypeStrings: NodeTypes,
+					Value:       op.Or{'?', '*', '+'},
+				},
+				spacing,
+			}),
+		},
+	})
+}
+
+// Primary <- Identifier !LEFTARROW / OPEN Expression CLOSE / Literal / Class / DOT
+func primary(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.Or{
+		op.And{Identifier, op.Not{Value: leftArrow}},
+		op.And{token('('), Expression, token(')')},
+		Literal,
+		Class,
+		op.And{
+			ast.Capture{
+				Type:        DotType,
+				TypeStrings: NodeTypes,
+				Value:       '.',
+			},
+			spacing,
+		},
+	})
+}
+
+// Literal <- ['] (!['] Char)* ['] Spacing / ["] (!["] Char)* ["] Spacing
+func Literal(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.And{
+		op.Or{
+			op.And{'\'', ast.Capture{
+				Type:        LiteralType,
+				TypeStrings: NodeTypes,
+				Value:       op.MinZero(op.And{op.Not{Value: '\''}, char}),
+			}, '\''},
+			op.And{'"', ast.Capture{
+				Type:        LiteralType,
+				TypeStrings: NodeTypes,
+				Value:       op.MinZero(op.And{op.Not{Value: '"'}, char}),
+			}, '"'},
+		},
+		spacing,
+	})
+}
+
+// Class <- '[' (!']' Range)* ']' Spacing
+func Class(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(op.And{
+		ast.Capture{
+			Type:        ClassType,
+			TypeStrings: NodeTypes,
+			Value: op.And{'[', op.MinZero(op.And{
+				op.Not{Value: ']'},
+				op.Or{
+					ast.Capture{
+						Type:        RangeType,
+						TypeStrings: NodeTypes,
+						Value:       op.And{Char, '-', Char},
+					},
+					Char,
+				},
+			}), ']'},
+		},
+		spacing,
+	})
+}
+
+// Char <- '\\' [nrt'"\[\]\\] / '\\' [0-2][0-7][0-7] / '\\' [0-7][0-7]? / !'\\' .
+func Char(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		Type:        CharType,
+		TypeStrings: NodeTypes,
+		Value:       char,
+	})
+}
+
+var (
+	octal = parser.CheckRuneRange('0', '7')
+	char  = op.Or{
+		op.And{'\\', op.Or{'n', 'r', 't', '\'', '"', '[', ']', '\\', '-'}},
+		op.And{'\\', parser.CheckRuneRange('0', '2'), octal, octal},
+		op.And{'\\', octal, op.Optional(octal)},
+		op.And{op.Not{Value: '\\'}, any},
+	}
+	identStart = op.Or{
+		parser.CheckRuneRange('a', 'z'),
+		parser.CheckRuneRange('A', 'Z'),
+		'_',
+	}
+	leftArrow = op.And{"<-", spacing}
+	any       = parser.AnyRune{}
+	endOfLine = op.Or{"\r\n", '\n', '\r'}
+	// Spacing <- (Space / Comment)*
+	spacing = op.MinZero(op.Or{
+		' ', '\t', endOfLine,
+		op.And{'#', op.MinZero(op.And{op.Not{Value: endOfLine}, any}), op.Or{endOfLine, parser.EOD}},
+	})
+)
+
+// token matches the given rune, followed by spacing.
+func token(r rune) op.And {
+	return op.And{r, spacing}
+}
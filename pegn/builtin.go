@@ -0,0 +1,41 @@
+package pegn
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+// Builtin contains the classes and tokens that can be used in every grammar
+// without defining them.
+var Builtin = map[string]interface{}{
+	// Classes
+	"any": parser.AnyRune{},
+	"alpha": op.Or{
+		parser.CheckRuneRange('A', 'Z'),
+		parser.CheckRuneRange('a', 'z'),
+	},
+	"alphanum": op.Or{
+		parser.CheckRuneRange('A', 'Z'),
+		parser.CheckRuneRange('a', 'z'),
+		parser.CheckRuneRange('0', '9'),
+	},
+	"digit": parser.CheckRuneRange('0', '9'),
+	"hexdig": op.Or{
+		parser.CheckRuneRange('0', '9'),
+		parser.CheckRuneRange('A', 'F'),
+		parser.CheckRuneRange('a', 'f'),
+	},
+	"lower": parser.CheckRuneRange('a', 'z'),
+	"upper": parser.CheckRuneRange('A', 'Z'),
+	"ws":    op.Or{' ', '\t', '\n', '\r'},
+
+	// Tokens
+	"TAB":  '\t',
+	"LF":   '\n',
+	"CR":   '\r',
+	"CRLF": "\r\n",
+	"SP":   ' ',
+	"DQ":   '"',
+	"SQ":   '\'',
+	"EOD":  parser.EOD,
+}
@@ -0,0 +1,297 @@
+package pegn
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Compile parses the given PEGN grammar and converts it to a grammar that can
+// be used to parse data directly. References to builtin classes and tokens are
+// resolved, unless the grammar (re)defines them. Grammars that import other
+// grammars need to be compiled with CompileWith or CompileFile.
+func Compile(data []byte) (*grammar.Grammar, error) {
+	return CompileWith(data, nil)
+}
+
+// CompileWith compiles the given PEGN grammar, like Compile. The grammars it
+// imports are loaded with the given function, based on their path.
+//
+//	import json 'json.pegn'
+//
+// The rules of an imported grammar are referenced by their qualified name (e.g.
+// json.Value). Defining a rule with a qualified name overrides the imported
+// rule, references to the rule within its own definition refer to the imported
+// rule (see grammar.Grammar.Override).
+//
+//	json.Value <- Comment* json.Value
+func CompileWith(data []byte, load func(path string) (*grammar.Grammar, error)) (*grammar.Grammar, error) {
+	n, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return compileNode(n, load)
+}
+
+// CompileFile compiles the PEGN grammar in the given file. Imported grammars are
+// loaded relative to the directory of the file.
+func CompileFile(path string) (*grammar.Grammar, error) {
+	return compileFile(path, make(map[string]bool))
+}
+
+// compileFile compiles the PEGN grammar in the given file, loading contains
+// the (absolute) paths of the files that are being compiled.
+func compileFile(path string, loading map[string]bool) (*grammar.Grammar, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if loading[abs] {
+		return nil, fmt.Errorf("pegn: import cycle: %s", path)
+	}
+	loading[abs] = true
+	defer delete(loading, abs)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return CompileWith(data, func(name string) (*grammar.Grammar, error) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		return compileFile(name, loading)
+	})
+}
+
+// CompileNode converts the given (parsed) PEGN grammar to a grammar. Grammars
+// that import other grammars need to be compiled with CompileWith or
+// CompileFile.
+func CompileNode(n *ast.Node) (*grammar.Grammar, error) {
+	return compileNode(n, nil)
+}
+
+func compileNode(n *ast.Node, load func(path string) (*grammar.Grammar, error)) (*grammar.Grammar, error) {
+	var (
+		g        = grammar.New()
+		defined  = make(map[string]bool)
+		imported = make(map[string]bool)
+	)
+	for _, imp := range n.Children() {
+		if imp.Type != ImportType {
+			continue
+		}
+		children := imp.Children()
+		namespace, path := children[0].Value, literalOf(children[1])
+		if strings.Contains(namespace, ".") {
+			return nil, fmt.Errorf("pegn: invalid namespace %s", namespace)
+		}
+		if imported[namespace] {
+			return nil, fmt.Errorf("pegn: namespace %s is already imported", namespace)
+		}
+		imported[namespace] = true
+		if load == nil {
+			return nil, fmt.Errorf("pegn: can not import %s without a loader", path)
+		}
+		other, err := load(path)
+		if err != nil {
+			return nil, fmt.Errorf("pegn: import %s: %v", namespace, err)
+		}
+		if err := g.Import(namespace, other); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range g.Rules() {
+		defined[r.Name] = true
+	}
+	for _, def := range n.Children() {
+		if def.Type == DefinitionType {
+			defined[def.FirstChild.Value] = true
+		}
+	}
+	c := compiler{defined: defined}
+	overridden := make(map[string]bool)
+	for _, def := range n.Children() {
+		if def.Type != DefinitionType {
+			continue
+		}
+		children := def.Children()
+		value, err := c.compile(children[2])
+		if err != nil {
+			return nil, err
+		}
+		r := &grammar.Rule{
+			Name:    children[0].Value,
+			Capture: children[1].Value == "<--",
+			Value:   value,
+		}
+		if !strings.Contains(r.Name, ".") {
+			if err := g.Add(r); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Qualified names override the rules of imported grammars.
+		if overridden[r.Name] {
+			return nil, fmt.Errorf("pegn: rule %s is already overridden", r.Name)
+		}
+		overridden[r.Name] = true
+		if err := g.Override(r); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.Check(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type compiler struct {
+	// defined contains the names of all the rules defined in the grammar.
+	defined map[string]bool
+}
+
+func (c compiler) compile(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case ExpressionType:
+		var or op.Or
+		for _, seq := range n.Children() {
+			v, err := c.compile(seq)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, v)
+		}
+		if len(or) == 1 {
+			return or[0], nil
+		}
+		return or, nil
+	case SequenceType:
+		var and op.And
+		for _, rule := range n.Children() {
+			v, err := c.compile(rule)
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, v)
+		}
+		if len(and) == 1 {
+			return and[0], nil
+		}
+		return and, nil
+	case PosLookType, NegLookType, PlainType:
+		children := n.Children()
+		v, err := c.compile(children[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 2 {
+			if v, err = quantify(v, children[1].Value); err != nil {
+				return nil, err
+			}
+		}
+		switch n.Type {
+		case PosLookType:
+			return op.Ensure{Value: v}, nil
+		case NegLookType:
+			return op.Not{Value: v}, nil
+		default:
+			return v, nil
+		}
+	case ReferenceType:
+		if !c.defined[n.Value] {
+			if v, ok := Builtin[n.Value]; ok {
+				return v, nil
+			}
+		}
+		return grammar.Ref{Name: n.Value}, nil
+	case LiteralType:
+		s := literalOf(n)
+		if r := []rune(s); len(r) == 1 {
+			return r[0], nil
+		}
+		return s, nil
+	case ClassType:
+		var or op.Or
+		for _, c := range n.Children() {
+			switch c.Type {
+			case RangeType:
+				chars := c.Children()
+				min, err := runeOf(chars[0])
+				if err != nil {
+					return nil, err
+				}
+				max, err := runeOf(chars[1])
+				if err != nil {
+					return nil, err
+				}
+				or = append(or, parser.CheckRuneRange(min, max))
+			default:
+				r, err := runeOf(c)
+				if err != nil {
+					return nil, err
+				}
+				or = append(or, r)
+			}
+		}
+		if len(or) == 1 {
+			return or[0], nil
+		}
+		return or, nil
+	case HexType, CharacterType:
+		return runeOf(n)
+	default:
+		return nil, fmt.Errorf("pegn: unexpected node %s", n.TypeString())
+	}
+}
+
+// literalOf returns the value of a Literal node, without quotes.
+func literalOf(n *ast.Node) string {
+	return strings.TrimSuffix(strings.TrimPrefix(n.Value, "'"), "'")
+}
+
+// runeOf returns the rune of a Character or Hex node.
+func runeOf(n *ast.Node) (rune, error) {
+	if n.Type == HexType {
+		v, err := strconv.ParseUint(n.Value[1:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("pegn: invalid hex %s: %v", n.Value, err)
+		}
+		return rune(v), nil
+	}
+	return []rune(n.Value)[0], nil
+}
+
+// quantify applies the given quantifier to the value.
+func quantify(v interface{}, q string) (interface{}, error) {
+	switch q {
+	case "?":
+		return op.Optional(v), nil
+	case "*":
+		return op.MinZero(v), nil
+	case "+":
+		return op.MinOne(v), nil
+	}
+	bounds := strings.Split(strings.Trim(q, "{}"), ",")
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return nil, fmt.Errorf("pegn: invalid quantifier %s", q)
+	}
+	if len(bounds) == 1 {
+		return op.Repeat(min, v), nil
+	}
+	if bounds[1] == "" {
+		return op.Min(min, v), nil
+	}
+	max, err := strconv.Atoi(bounds[1])
+	if err != nil || max < min {
+		return nil, fmt.Errorf("pegn: invalid quantifier %s", q)
+	}
+	return op.MinMax(min, max, v), nil
+}
@@ -0,0 +1,155 @@
+package pegn_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/pegn"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ExampleCompile() {
+	g, err := pegn.Compile([]byte(`
+Expr    <-- Integer (Op Integer)*
+Op      <-- [+-]
+Integer <-- digit{1,3} / x28 Expr x29
+`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(g.Parse("Expr", []byte("1+(20-300)")))
+	fmt.Println(g.Parse("Expr", []byte("1000")))
+	// Output:
+	// ["Expr",[["Integer","1"],["Op","+"],["Integer",[["Expr",[["Integer","20"],["Op","-"],["Integer","300"]]]]]]] <nil>
+	// ["Expr",[["Integer","100"]]] <nil>
+}
+
+func ExampleCompile_undefined() {
+	_, err := pegn.Compile([]byte("List <-- '[' Item (',' Item)* ']'\n"))
+	fmt.Println(err)
+	// Output:
+	// grammar: undefined rules: [Item]
+}
+
+// TestCompile checks whether the compiled PEGN grammar is able to parse all the
+// PEGN grammars, including itself.
+func TestCompile(t *testing.T) {
+	data, err := ioutil.ReadFile("grammar.pegn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := pegn.Compile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob("../examples/*/*.pegn")
+	files = append(files, "../ast/grammar.pegn", "grammar.pegn")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := pegn.Parse(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := g.Parse("Grammar", data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		// Both trees should have the same structure.
+		if len(actual.Children()) != len(expected.Children()) {
+			t.Errorf("%s: %d nodes, expected %d", file, len(actual.Children()), len(expected.Children()))
+		}
+	}
+}
+
+func ExampleCompileWith() {
+	files := map[string]string{
+		"json.pegn": `
+Value  <-- Array / Number
+Array  <-- '[' Value (',' Value)* ']'
+Number <-- digit+
+`,
+	}
+	load := func(path string) (*grammar.Grammar, error) {
+		return pegn.Compile([]byte(files[path]))
+	}
+	// Extend the values of the imported grammar with comments.
+	g, err := pegn.CompileWith([]byte(`
+import json 'json.pegn'
+
+Document   <-- json.Value
+json.Value  <- Comment? json.Value
+Comment    <-- '/*' (!'*/' any)* '*/'
+`), load)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(g.Parse("Document", []byte("[1,/*two*/2]")))
+	// Output:
+	// ["Document",[["json.Array",[["json.Number","1"],["Comment","/*two*/"],["json.Number","2"]]]]] <nil>
+}
+
+func TestCompileFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pegn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, data := range map[string]string{
+		"list.pegn":     "List <-- '[' item.Item (',' item.Item)* ']'\nimport item 'lib/item.pegn'\n",
+		"lib/item.pegn": "Item <-- digit+\n",
+		"cycle.pegn":    "import self 'cycle.pegn'\nA <- 'a'\n",
+	} {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := pegn.CompileFile(filepath.Join(dir, "list.pegn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := g.Parse("List", []byte("[1,23]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := n.String(); s != `["List",[["item.Item","1"],["item.Item","23"]]]` {
+		t.Error(s)
+	}
+
+	if _, err := pegn.CompileFile(filepath.Join(dir, "cycle.pegn")); err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("expected an import cycle, got %v", err)
+	}
+}
+
+func TestCompileWith_errors(t *testing.T) {
+	load := func(path string) (*grammar.Grammar, error) {
+		return pegn.Compile([]byte("Item <-- digit+\n"))
+	}
+	for _, test := range []struct {
+		grammar, err string
+	}{
+		{"import a 'a.pegn'\nimport a 'b.pegn'\n", "pegn: namespace a is already imported"},
+		{"import a.b 'a.pegn'\n", "pegn: invalid namespace a.b"},
+		{"import a 'a.pegn'\na.Other <- 'x'\n", "grammar: rule a.Other is not defined"},
+		{"import a 'a.pegn'\na.Item <- 'x'\na.Item <- 'y'\n", "pegn: rule a.Item is already overridden"},
+		{"import a 'a.pegn'\nList <- a.Other\n", "grammar: undefined rules: [a.Other]"},
+	} {
+		if _, err := pegn.CompileWith([]byte(test.grammar), load); err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+	if _, err := pegn.Compile([]byte("import a 'a.pegn'\n")); err == nil {
+		t.Error("expected an error without a loader")
+	}
+}
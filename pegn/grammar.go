@@ -0,0 +1,604 @@
+// Package pegn parses grammars written in PEGN, the grammar notation used to
+// describe the grammars of the examples in this repository. The grammar of PEGN
+// itself is described in grammar.pegn.
+package pegn
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// Parse parses the given PEGN grammar.
+func Parse(data []byte) (*ast.Node, error) {
+	return ast.Parse(data, Grammar)
+}
+
+func Grammar(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        GrammarType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				op.Optional(
+					Meta,
+				),
+				op.MinZero(
+					op.Or{
+						Definition,
+						Import,
+						op.And{
+							spacing,
+							op.Or{
+								op.And{
+									op.Optional(
+										Comment,
+									),
+									endLine,
+								},
+								Comment,
+							},
+						},
+					},
+				),
+				parser.EOD,
+			},
+		},
+	)
+}
+
+func Meta(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        MetaType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				"# ",
+				language,
+				" (",
+				version,
+				") ",
+				home,
+				endLine,
+			},
+		},
+	)
+}
+
+func language(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        LanguageType,
+			TypeStrings: NodeTypes,
+			Value: op.MinOne(
+				op.Or{
+					Builtin["alphanum"],
+					'-',
+					'_',
+				},
+			),
+		},
+	)
+}
+
+func version(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        VersionType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'v',
+				op.MinOne(
+					Builtin["digit"],
+				),
+				op.MinZero(
+					op.And{
+						'.',
+						op.MinOne(
+							Builtin["digit"],
+						),
+					},
+				),
+				op.Optional(
+					op.And{
+						'-',
+						op.MinOne(
+							op.Or{
+								Builtin["alphanum"],
+								'.',
+							},
+						),
+					},
+				),
+			},
+		},
+	)
+}
+
+func home(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        HomeType,
+			TypeStrings: NodeTypes,
+			Value: op.MinOne(
+				op.And{
+					op.Not{
+						Value: op.Or{
+							' ',
+							'\t',
+							endLine,
+						},
+					},
+					Builtin["any"],
+				},
+			),
+		},
+	)
+}
+
+func Comment(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        CommentType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'#',
+				op.MinZero(
+					op.And{
+						op.Not{
+							Value: endLine,
+						},
+						Builtin["any"],
+					},
+				),
+			},
+		},
+	)
+}
+
+func Import(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        ImportType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				"import",
+				op.MinOne(
+					' ',
+				),
+				Identifier,
+				op.MinOne(
+					' ',
+				),
+				literal,
+				spacing,
+				op.Optional(
+					Comment,
+				),
+				op.Or{
+					endLine,
+					parser.EOD,
+				},
+			},
+		},
+	)
+}
+
+func Definition(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        DefinitionType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				Identifier,
+				spacing,
+				operator,
+				spacing,
+				Expression,
+				spacing,
+				op.Optional(
+					Comment,
+				),
+				op.Or{
+					endLine,
+					parser.EOD,
+				},
+			},
+		},
+	)
+}
+
+func Identifier(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        IdentifierType,
+			TypeStrings: NodeTypes,
+			Value:       qualified,
+		},
+	)
+}
+
+func identifier(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.And{
+			Builtin["alpha"],
+			op.MinZero(
+				op.Or{
+					Builtin["alphanum"],
+					'_',
+				},
+			),
+		},
+	)
+}
+
+func qualified(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.And{
+			identifier,
+			op.Optional(
+				op.And{
+					'.',
+					identifier,
+				},
+			),
+		},
+	)
+}
+
+func operator(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        OperatorType,
+			TypeStrings: NodeTypes,
+			Value: op.Or{
+				"<--",
+				"<-",
+			},
+		},
+	)
+}
+
+func Expression(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        ExpressionType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				sequence,
+				op.MinZero(
+					op.And{
+						spacing,
+						'/',
+						spacing,
+						sequence,
+					},
+				),
+			},
+		},
+	)
+}
+
+func sequence(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        SequenceType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				rule,
+				op.MinZero(
+					op.And{
+						spacing,
+						rule,
+					},
+				),
+			},
+		},
+	)
+}
+
+func rule(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.Or{
+			posLook,
+			negLook,
+			plain,
+		},
+	)
+}
+
+func posLook(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        PosLookType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'&',
+				primary,
+				op.Optional(
+					quantifier,
+				),
+			},
+		},
+	)
+}
+
+func negLook(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        NegLookType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'!',
+				primary,
+				op.Optional(
+					quantifier,
+				),
+			},
+		},
+	)
+}
+
+func plain(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        PlainType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				primary,
+				op.Optional(
+					quantifier,
+				),
+			},
+		},
+	)
+}
+
+func primary(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.Or{
+			hex,
+			reference,
+			literal,
+			class,
+			op.And{
+				'(',
+				spacing,
+				Expression,
+				spacing,
+				')',
+			},
+		},
+	)
+}
+
+func reference(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        ReferenceType,
+			TypeStrings: NodeTypes,
+			Value:       qualified,
+		},
+	)
+}
+
+func literal(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        LiteralType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'\'',
+				op.MinOne(
+					op.And{
+						op.Not{
+							Value: '\'',
+						},
+						op.Not{
+							Value: endLine,
+						},
+						Builtin["any"],
+					},
+				),
+				'\'',
+			},
+		},
+	)
+}
+
+func class(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        ClassType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'[',
+				op.MinOne(
+					op.Or{
+						runeRange,
+						char,
+					},
+				),
+				']',
+			},
+		},
+	)
+}
+
+func runeRange(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        RangeType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				char,
+				'-',
+				char,
+			},
+		},
+	)
+}
+
+func char(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.Or{
+			hex,
+			character,
+		},
+	)
+}
+
+func character(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        CharacterType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				op.Not{
+					Value: ']',
+				},
+				op.Not{
+					Value: endLine,
+				},
+				Builtin["any"],
+			},
+		},
+	)
+}
+
+func hex(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        HexType,
+			TypeStrings: NodeTypes,
+			Value: op.And{
+				'x',
+				op.MinOne(
+					Builtin["hexdig"],
+				),
+				op.Not{
+					Value: op.Or{
+						Builtin["alphanum"],
+						'_',
+					},
+				},
+			},
+		},
+	)
+}
+
+func quantifier(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		ast.Capture{
+			Type:        QuantifierType,
+			TypeStrings: NodeTypes,
+			Value: op.Or{
+				'?',
+				'*',
+				'+',
+				op.And{
+					'{',
+					op.MinOne(
+						Builtin["digit"],
+					),
+					op.Optional(
+						op.And{
+							',',
+							op.MinZero(
+								Builtin["digit"],
+							),
+						},
+					),
+					'}',
+				},
+			},
+		},
+	)
+}
+
+func spacing(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.MinZero(
+			op.Or{
+				' ',
+				'\t',
+				op.And{
+					endLine,
+					op.Ensure{
+						Value: op.Or{
+							' ',
+							'\t',
+						},
+					},
+				},
+			},
+		),
+	)
+}
+
+func endLine(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(
+		op.Or{
+			'\n',
+			"\r\n",
+			'\r',
+		},
+	)
+}
+
+// Node Types
+const (
+	Unknown = iota
+
+	// PEGN (github.com/di-wu/parser/pegn)
+	GrammarType    // 001
+	MetaType       // 002
+	LanguageType   // 003
+	VersionType    // 004
+	HomeType       // 005
+	CommentType    // 006
+	DefinitionType // 007
+	IdentifierType // 008
+	OperatorType   // 009
+	ExpressionType // 010
+	SequenceType   // 011
+	PosLookType    // 012
+	NegLookType    // 013
+	PlainType      // 014
+	ReferenceType  // 015
+	LiteralType    // 016
+	ClassType      // 017
+	RangeType      // 018
+	CharacterType  // 019
+	HexType        // 020
+	QuantifierType // 021
+	ImportType     // 022
+)
+
+var NodeTypes = []string{
+	"UNKNOWN",
+
+	// PEGN (github.com/di-wu/parser/pegn)
+	"Grammar",
+	"Meta",
+	"Language",
+	"Version",
+	"Home",
+	"Comment",
+	"Definition",
+	"Identifier",
+	"Operator",
+	"Expression",
+	"Sequence",
+	"PosLook",
+	"NegLook",
+	"Plain",
+	"Reference",
+	"Literal",
+	"Class",
+	"Range",
+	"Character",
+	"Hex",
+	"Quantifier",
+	"Import",
+}
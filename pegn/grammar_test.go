@@ -0,0 +1,37 @@
+package pegn_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/pegn"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func ExampleParse() {
+	n, err := pegn.Parse([]byte("Expr <-- Integer ('+' Integer)*\nInteger <-- [0-9]+\n"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, def := range n.Children() {
+		fmt.Println(def)
+	}
+	// Output:
+	// ["Definition",[["Identifier","Expr"],["Operator","<--"],["Expression",[["Sequence",[["Plain",[["Reference","Integer"]]],["Plain",[["Expression",[["Sequence",[["Plain",[["Literal","'+'"]]],["Plain",[["Reference","Integer"]]]]]]],["Quantifier","*"]]]]]]]]]
+	// ["Definition",[["Identifier","Integer"],["Operator","<--"],["Expression",[["Sequence",[["Plain",[["Class",[["Range",[["Character","0"],["Character","9"]]]]],["Quantifier","+"]]]]]]]]]
+}
+
+func TestParse(t *testing.T) {
+	files, _ := filepath.Glob("../examples/*/*.pegn")
+	files = append(files, "../ast/grammar.pegn", "grammar.pegn")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pegn.Parse(data); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
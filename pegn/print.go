@@ -0,0 +1,411 @@
+package pegn
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Print writes the given rules as a PEGN document. Rules that capture (or of
+// which the value is an ast.Capture) use the `<--` operator. References to
+// other rules (grammar.Ref, ast.LoopUp and ast.ParseNode) are printed by name,
+// captures within a rule are printed as a reference to an additional rule named
+// after their type string. Values that are equal to a builtin class or token
+// are printed by their builtin name.
+//
+// Values that can not be expressed in PEGN (e.g. op.XOr, op.BackRef or classes
+// created with parser.CheckRuneFunc) result in an error. The labels of op.Expect
+// and the skipping of op.Token are not retained.
+func Print(w io.Writer, rules []*grammar.Rule) error {
+	pr := printer{
+		defined: make(map[string]bool),
+	}
+	for _, r := range rules {
+		pr.defined[r.Name] = true
+	}
+	var defs [][3]string
+	for i := 0; i < len(rules); i++ {
+		r := rules[i]
+		if c, ok := r.Value.(ast.Capture); ok {
+			r = &grammar.Rule{Name: r.Name, Capture: true, Value: c.Value}
+		}
+		if !isIdentifier(r.Name) {
+			return fmt.Errorf("pegn: invalid rule name %q", r.Name)
+		}
+		v, err := pr.print(r.Value, levelExpression)
+		if err != nil {
+			return fmt.Errorf("pegn: rule %s: %v", r.Name, err)
+		}
+		operator := "<-"
+		if r.Capture {
+			operator = "<--"
+		}
+		defs = append(defs, [3]string{r.Name, operator, v})
+		// Captures within the rule become rules of their own.
+		rules = append(rules, pr.extra...)
+		pr.extra = nil
+	}
+
+	var width int
+	for _, d := range defs {
+		if width < len(d[0]) {
+			width = len(d[0])
+		}
+	}
+	var b bytes.Buffer
+	for _, d := range defs {
+		fmt.Fprintf(&b, "%-*s %3s %s\n", width, d[0], d[1], d[2])
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// PrintTable writes the rules of the given table (e.g. used by ast.LoopUp) as
+// a PEGN document, sorted by name.
+func PrintTable(w io.Writer, table map[string]interface{}) error {
+	var names []string
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var rules []*grammar.Rule
+	for _, name := range names {
+		rules = append(rules, &grammar.Rule{Name: name, Value: table[name]})
+	}
+	return Print(w, rules)
+}
+
+// Sprint returns the PEGN expression of the given value. Captures are
+// printed as a reference to a rule named after their type string.
+func Sprint(i interface{}) (string, error) {
+	pr := printer{
+		defined: make(map[string]bool),
+	}
+	return pr.print(i, levelExpression)
+}
+
+// The precedence levels of PEGN expressions.
+const (
+	levelExpression = iota // a / b
+	levelSequence          // a b
+	levelRule              // !a
+	levelPlain             // a*
+	levelPrimary           // 'a', [a-z], (a)
+)
+
+type printer struct {
+	// defined contains the names of all the rules.
+	defined map[string]bool
+	// extra contains the rules of the captures within the current rule.
+	extra []*grammar.Rule
+}
+
+// print returns the PEGN expression of the value, it is grouped if its level is
+// lower than the given level.
+func (pr *printer) print(i interface{}, level int) (string, error) {
+	s, l, err := pr.expression(i)
+	if err != nil {
+		return "", err
+	}
+	if l < level {
+		return "(" + s + ")", nil
+	}
+	return s, nil
+}
+
+// expression returns the PEGN expression of the value and its level.
+func (pr *printer) expression(i interface{}) (string, int, error) {
+	if name, ok := builtinName(i); ok {
+		return name, levelPrimary, nil
+	}
+	switch v := i.(type) {
+	case int:
+		return pr.expression(rune(v))
+	case rune:
+		return printRune(v), levelPrimary, nil
+	case string:
+		return printString(v)
+	case []interface{}:
+		return pr.expression(op.And(v))
+	case op.And:
+		if len(v) == 0 {
+			return "", 0, fmt.Errorf("empty sequence")
+		}
+		if len(v) == 1 {
+			return pr.expression(v[0])
+		}
+		s, err := pr.printAll(v, levelRule, " ")
+		return s, levelSequence, err
+	case op.Or:
+		if len(v) == 1 {
+			return pr.expression(v[0])
+		}
+		if s, level, ok := printClass(v); ok {
+			return s, level, nil
+		}
+		s, err := pr.printAll(v, levelSequence, " / ")
+		return s, levelExpression, err
+	case op.Not:
+		s, err := pr.print(v.Value, levelPlain)
+		return "!" + s, levelRule, err
+	case op.Ensure:
+		s, err := pr.print(v.Value, levelPlain)
+		return "&" + s, levelRule, err
+	case op.Range:
+		s, err := pr.print(v.Value, levelPrimary)
+		return s + printQuantifier(v), levelPlain, err
+	case op.Token:
+		return pr.expression(v.Value)
+	case op.Expect:
+		return pr.expression(v.Value)
+	case grammar.Ref:
+		return v.Name, levelPrimary, nil
+	case ast.LoopUp:
+		return v.Key, levelPrimary, nil
+	case ast.Capture:
+		name := v.String()
+		if !isIdentifier(name) {
+			return "", 0, fmt.Errorf("capture %s has no valid name", name)
+		}
+		if !pr.defined[name] {
+			pr.defined[name] = true
+			pr.extra = append(pr.extra, &grammar.Rule{
+				Name:    name,
+				Capture: true,
+				Value:   v.Value,
+			})
+		}
+		return name, levelPrimary, nil
+	case func(p *ast.Parser) (*ast.Node, error), ast.ParseNode:
+		if name, ok := ast.RuleName(v); ok && isIdentifier(name) {
+			return name, levelPrimary, nil
+		}
+	case parser.RuneClass:
+		if v.CaseInsensitive {
+			return printCI(v.Rune), levelPrimary, nil
+		}
+		return printRune(v.Rune), levelPrimary, nil
+	case parser.RuneRangeClass:
+		s, level, _ := printClass(op.Or{v})
+		return s, level, nil
+	case parser.StringClass:
+		if !v.CaseInsensitive {
+			return printString(v.Value)
+		}
+		var and []string
+		for _, r := range v.Value {
+			and = append(and, printCI(r))
+		}
+		if len(and) == 1 {
+			return and[0], levelPrimary, nil
+		}
+		return strings.Join(and, " "), levelSequence, nil
+	case parser.IntegerClass:
+		if !v.LeadingZeros {
+			return printString(strconv.Itoa(v.Value))
+		}
+		if v.Value == 0 {
+			return "'0'+", levelPlain, nil
+		}
+		and := []string{"'0'*", "'" + strconv.Itoa(abs(v.Value)) + "'"}
+		if v.Value < 0 {
+			and = append([]string{"'-'"}, and...)
+		}
+		return strings.Join(and, " "), levelSequence, nil
+	case parser.AnyRune:
+		return "any", levelPrimary, nil
+	case parser.CharSet:
+		s, level, ok := printCharSet(v)
+		if !ok {
+			return "", 0, fmt.Errorf("empty class")
+		}
+		return s, level, nil
+	}
+	return "", 0, fmt.Errorf("can not express %T %s in PEGN", i, parser.Stringer(i))
+}
+
+func (pr *printer) printAll(values []interface{}, level int, sep string) (string, error) {
+	all := make([]string, len(values))
+	for i, v := range values {
+		s, err := pr.print(v, level)
+		if err != nil {
+			return "", err
+		}
+		all[i] = s
+	}
+	return strings.Join(all, sep), nil
+}
+
+// builtinName returns the name of the builtin class or token that is equal to the
+// given value.
+func builtinName(i interface{}) (string, bool) {
+	switch i.(type) {
+	case int, rune, string, op.Or, parser.RuneClass, parser.RuneRangeClass, parser.StringClass, parser.AnyRune:
+	default:
+		return "", false
+	}
+	var names []string
+	for name := range Builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if reflect.DeepEqual(i, Builtin[name]) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// printQuantifier returns the PEGN quantifier of the range.
+func printQuantifier(r op.Range) string {
+	min := r.Min
+	if min < 0 {
+		min = 0
+	}
+	switch {
+	case min == 0 && r.Max == -1:
+		return "*"
+	case min == 1 && r.Max == -1:
+		return "+"
+	case min == 0 && r.Max == 1:
+		return "?"
+	case r.Max == -1:
+		return fmt.Sprintf("{%d,}", min)
+	case r.Max <= min:
+		return fmt.Sprintf("{%d}", min)
+	default:
+		return fmt.Sprintf("{%d,%d}", min, r.Max)
+	}
+}
+
+// printRune returns the PEGN expression of a single rune.
+func printRune(r rune) string {
+	if r == parser.EOD {
+		return "EOD"
+	}
+	if name, ok := builtinName(r); ok {
+		return name
+	}
+	if printable(r) {
+		return "'" + string(r) + "'"
+	}
+	return printHex(r)
+}
+
+// printCI returns a class that matches both the upper and lower case
+// of the given rune.
+func printCI(r rune) string {
+	lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+	if lower == upper {
+		return printRune(r)
+	}
+	s, _, _ := printClass(op.Or{upper, lower})
+	return s
+}
+
+// printString returns the PEGN expression of the string. Runes that can not be
+// part of a literal are printed as hex values.
+func printString(s string) (string, int, error) {
+	if s == "" {
+		return "", 0, fmt.Errorf("empty string")
+	}
+	if name, ok := builtinName(s); ok {
+		return name, levelPrimary, nil
+	}
+	var (
+		parts []string
+		lit   []rune
+	)
+	for _, r := range s {
+		if printable(r) {
+			lit = append(lit, r)
+			continue
+		}
+		if len(lit) != 0 {
+			parts = append(parts, "'"+string(lit)+"'")
+			lit = nil
+		}
+		parts = append(parts, printRune(r))
+	}
+	if len(lit) != 0 {
+		parts = append(parts, "'"+string(lit)+"'")
+	}
+	if len(parts) == 1 {
+		return parts[0], levelPrimary, nil
+	}
+	return strings.Join(parts, " "), levelSequence, nil
+}
+
+// printClass returns the PEGN class of the alternatives, if they are all (ranges
+// of) runes, see parser.CharSet.String.
+func printClass(or op.Or) (string, int, bool) {
+	var cs parser.CharSet
+	for _, v := range or {
+		switch v := v.(type) {
+		case rune:
+			cs = cs.Union(parser.CharSetOf(v))
+		case parser.RuneClass:
+			if v.CaseInsensitive {
+				return "", 0, false
+			}
+			cs = cs.Union(parser.CharSetOf(v.Rune))
+		case parser.RuneRangeClass:
+			cs = cs.Union(parser.NewCharSet(v))
+		default:
+			return "", 0, false
+		}
+	}
+	return printCharSet(cs)
+}
+
+// printCharSet returns the PEGN class of the set. Sets that can not be written
+// as a single class are written as alternatives.
+func printCharSet(cs parser.CharSet) (string, int, bool) {
+	if cs.IsEmpty() {
+		return "", 0, false
+	}
+	s := cs.String()
+	if strings.Contains(s, " / ") {
+		return s, levelExpression, true
+	}
+	return s, levelPrimary, true
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func printHex(r rune) string {
+	return fmt.Sprintf("x%02X", r)
+}
+
+// printable returns whether the rune can be part of a literal.
+func printable(r rune) bool {
+	return r != '\'' && r != parser.EOD && unicode.IsPrint(r)
+}
+
+// isIdentifier returns whether the name is a valid PEGN identifier.
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i != 0 && ('0' <= r && r <= '9' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,138 @@
+package pegn_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"github.com/di-wu/parser/pegn"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func ExamplePrint() {
+	_ = pegn.Print(os.Stdout, []*grammar.Rule{
+		{Name: "List", Capture: true, Value: op.And{
+			'[', grammar.Ref{Name: "Item"},
+			op.MinZero(op.And{',', op.Optional(' '), grammar.Ref{Name: "Item"}}),
+			']',
+		}},
+		{Name: "Item", Value: op.Or{
+			ast.Capture{TypeStrings: []string{"Number"}, Value: op.MinMax(1, 3, parser.CheckRuneRange('0', '9'))},
+			ast.Capture{TypeStrings: []string{"Name"}, Value: op.MinOne(op.Or{
+				parser.CheckRuneRange('a', 'z'), '_',
+			})},
+			parser.CheckStringCI("nil"),
+		}},
+	})
+	// Output:
+	// List   <-- '[' Item (',' SP? Item)* ']'
+	// Item    <- Number / Name / [Nn] [Ii] [Ll]
+	// Number <-- digit{1,3}
+	// Name   <-- [_a-z]+
+}
+
+func ExamplePrintTable() {
+	table := map[string]interface{}{
+		"Value": ast.Capture{
+			TypeStrings: []string{"Value"},
+			Value:       op.Or{ast.LoopUp{Key: "Array", Table: nil}, parser.CheckInteger(0, false)},
+		},
+		"Array": op.And{'[', op.MinZero(ast.LoopUp{Key: "Value"}), ']'},
+	}
+	_ = pegn.PrintTable(os.Stdout, table)
+	// Output:
+	// Array  <- '[' Value* ']'
+	// Value <-- Array / '0'
+}
+
+func ExamplePrint_unsupported() {
+	err := pegn.Print(os.Stdout, []*grammar.Rule{
+		{Name: "Sign", Value: op.XOr{'+', '-'}},
+	})
+	fmt.Println(err)
+	// Output:
+	// pegn: rule Sign: can not express op.XOr xor['+' '-'] in PEGN
+}
+
+// TestPrint checks whether the printed PEGN grammar can be compiled again and
+// is still able to parse all the PEGN grammars.
+func TestPrint(t *testing.T) {
+	data, err := ioutil.ReadFile("grammar.pegn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := pegn.Compile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := pegn.Print(&b, g.Rules()); err != nil {
+		t.Fatal(err)
+	}
+	printed, err := pegn.Compile(b.Bytes())
+	if err != nil {
+		t.Fatalf("%v\n%s", err, b.String())
+	}
+	files, _ := filepath.Glob("../examples/*/*.pegn")
+	files = append(files, "../ast/grammar.pegn", "grammar.pegn")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := g.Parse("Grammar", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := printed.Parse("Grammar", data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if actual.String() != expected.String() {
+			t.Errorf("%s: trees are not equal", file)
+		}
+	}
+}
+
+// TestPrint_class checks whether the printed classes round-trip, e.g. the runes
+// that follow a hex value are not printed as part of that value ("\nA" is not
+// [x0AA]) and the runes that have a meaning within a class are escaped.
+func TestPrint_class(t *testing.T) {
+	for _, test := range []struct {
+		value    interface{}
+		accepted string
+		rejected string
+	}{
+		{op.Or{'\n', 'A', '-', '0', parser.CheckRuneRange('x', 'z')}, "\nA-0xy", "\u00AA 1"},
+		{op.Or{'\t', '\n', '#'}, "\t\n#", "x0 "},
+		{op.Or{parser.CheckRuneRange(0x00, 0x1F), 'A', 'B', rune(0x7F)}, "\x00\x1FAB\x7F", "x1 C"},
+		{op.Or{']', '\\', parser.CheckRuneRange('[', ']')}, "\\[]", "x5^"},
+		{parser.NewCharSet(parser.CheckRuneRange(0x00, 0x1F), parser.CheckRuneRange('A', 'B')).Union(parser.CharSetOf(0x7F)), "\x00\x1FAB\x7F", "x1 C"},
+		{parser.CharSetOf('\\', '[', ']', 'a'), "\\[]a", "x5 \n"},
+	} {
+		var b bytes.Buffer
+		if err := pegn.Print(&b, []*grammar.Rule{{Name: "Class", Value: test.value}}); err != nil {
+			t.Fatal(err)
+		}
+		g, err := pegn.Compile(b.Bytes())
+		if err != nil {
+			t.Fatalf("%v\n%s", err, b.String())
+		}
+		for _, r := range test.accepted {
+			if _, err := g.Parse("Class", []byte(string(r))); err != nil {
+				t.Errorf("%q: %v\n%s", r, err, b.String())
+			}
+		}
+		for _, r := range test.rejected {
+			if _, err := g.Parse("Class", []byte(string(r))); err == nil {
+				t.Errorf("%q: expected an error\n%s", r, b.String())
+			}
+		}
+	}
+}
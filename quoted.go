@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	hexDigits      = NewCharSet(CheckRuneRange('0', '9'), CheckRuneRange('A', 'F'), CheckRuneRange('a', 'f'))
+	highSurrogates = NewCharSet(CheckRuneRange(0xD800, 0xDBFF))
+	lowSurrogates  = NewCharSet(CheckRuneRange(0xDC00, 0xDFFF))
+)
+
+// Unquote returns the decoded value of the given string literal, see
+// op.Quoted. An error is returned if the string is not a single literal.
+func Unquote(q op.Quoted, s string) (string, error) {
+	p, err := New([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	_, decoded, err := p.expectQuoted(q)
+	if err != nil {
+		return "", err
+	}
+	if !p.Done() {
+		return "", &ExpectError{
+			Message: fmt.Sprintf("%q is not a single string literal", s),
+		}
+	}
+	return decoded, nil
+}
+
+// expectQuoted checks whether the buffer contains the given string literal. It
+// returns the cursor of the closing quote and the decoded string. Resets the
+// parser if it does not match.
+func (p *Parser) expectQuoted(q op.Quoted) (*Cursor, string, error) {
+	start := p.Mark()
+	quotes := q.Quotes
+	if len(quotes) == 0 {
+		quotes = []string{`"`}
+	}
+	var quote string
+	for _, v := range quotes {
+		if _, ok := p.Check(v); ok {
+			quote = v
+			break
+		}
+	}
+	if quote == "" {
+		return nil, "", p.ExpectedParseError(q, start, start)
+	}
+
+	var decoded strings.Builder
+	for {
+		current := p.Mark()
+		if last, ok := p.Check(quote); ok {
+			return last, decoded.String(), nil
+		}
+		switch r := p.Current(); {
+		case r == EOD, !q.Multiline && (r == '\n' || r == '\r'):
+			err := p.ExpectedParseError(quote, current, current)
+			p.Jump(start)
+			return nil, "", err
+		case q.Escape != 0 && r == q.Escape:
+			r, err := p.unescape(q)
+			if err != nil {
+				p.Jump(start)
+				// Labeled, the parser does not backtrack.
+				return nil, "", err
+			}
+			decoded.WriteRune(r)
+		default:
+			decoded.WriteRune(r)
+			p.Next()
+		}
+	}
+}
+
+// unescape decodes the escape sequence at the current position of the parser.
+// Returns an error with the reason as label if the sequence is invalid.
+func (p *Parser) unescape(q op.Quoted) (rune, error) {
+	start := p.Mark()
+	r, err := p.unescapeRune(q)
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	if lowSurrogates.Contains(r) {
+		return 0, p.surrogateError(highSurrogates, start)
+	}
+	// The second half of the pair.
+	second := p.Mark()
+	if p.Current() != q.Escape || p.Peek().Rune != 'u' {
+		return 0, p.surrogateError(lowSurrogates, start)
+	}
+	low, err := p.unescapeRune(q)
+	if err != nil {
+		return 0, err
+	}
+	if !lowSurrogates.Contains(low) {
+		return 0, p.surrogateError(lowSurrogates, second)
+	}
+	return utf16.DecodeRune(r, low), nil
+}
+
+// unescapeRune decodes a single escape sequence, surrogates are returned as is.
+func (p *Parser) unescapeRune(q op.Quoted) (rune, error) {
+	p.Next() // Escape rune.
+	r := p.Current()
+	if v, ok := q.Escapes[r]; ok {
+		p.Next()
+		return v, nil
+	}
+	if r != 'u' || !q.Unicode {
+		escapes := make([]rune, 0, len(q.Escapes)+1)
+		for r := range q.Escapes {
+			escapes = append(escapes, r)
+		}
+		if q.Unicode {
+			escapes = append(escapes, 'u')
+		}
+		return 0, p.escapeError("invalid escape sequence", CharSetOf(escapes...))
+	}
+	p.Next()
+	var v rune
+	for i := 0; i < 4; i++ {
+		r := p.Current()
+		if !hexDigits.Contains(r) {
+			return 0, p.escapeError("invalid unicode escape", hexDigits)
+		}
+		switch {
+		case r <= '9':
+			v = v*16 + r - '0'
+		case r <= 'F':
+			v = v*16 + r - 'A' + 10
+		default:
+			v = v*16 + r - 'a' + 10
+		}
+		p.Next()
+	}
+	return v, nil
+}
+
+// escapeError returns an error for the rune at the current position of the
+// parser, within an escape sequence.
+func (p *Parser) escapeError(label string, expected interface{}) *ExpectedParseError {
+	err := p.ExpectedParseError(expected, p.Mark(), p.Mark())
+	err.Label = label
+	return err
+}
+
+// surrogateError returns an error for the surrogate of which the escape
+// sequence starts at the given cursor.
+func (p *Parser) surrogateError(expected CharSet, start *Cursor) *ExpectedParseError {
+	err := p.ExpectedParseError(expected, start, p.LookBack())
+	err.Conflict = *start
+	err.Label = "unpaired surrogate"
+	return err
+}
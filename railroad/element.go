@@ -0,0 +1,249 @@
+package railroad
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// radius of the curves of the tracks.
+	radius = 10
+	// gap between two elements of a sequence.
+	gap = 10
+	// padding within boxes and groups.
+	padding = 10
+	// boxHeight is the height of terminals and non-terminals.
+	boxHeight = 22
+	// charWidth is the (estimated) width of a character.
+	charWidth = 8
+	// labelHeight is the height of the labels of groups and loops.
+	labelHeight = 14
+)
+
+// element is a part of a diagram. It is drawn on a horizontal track, which
+// enters on the left and leaves on the right.
+type element interface {
+	// size returns the width of the element, and its height above and below
+	// the track.
+	size() (width, up, down int)
+	// draw draws the element at the given position of the track.
+	draw(s *svg, x, y int)
+}
+
+// box is a terminal or a non-terminal.
+type box struct {
+	text string
+	// class is the CSS class of the box: terminal, nonterminal or special.
+	class string
+	// href is the link of a non-terminal, if any.
+	href string
+}
+
+func (b box) size() (int, int, int) {
+	return utf8.RuneCountInString(b.text)*charWidth + 2*padding, boxHeight / 2, boxHeight / 2
+}
+
+func (b box) draw(s *svg, x, y int) {
+	w, up, _ := b.size()
+	if b.href != "" {
+		s.printf(`<a href="%s">`, html.EscapeString(b.href))
+	}
+	rx := 0
+	if b.class == "terminal" {
+		rx = boxHeight / 2
+	}
+	s.printf(`<rect class="%s" x="%d" y="%d" width="%d" height="%d" rx="%d"/>`, b.class, x, y-up, w, boxHeight, rx)
+	s.printf(`<text x="%d" y="%d">%s</text>`, x+w/2, y+4, html.EscapeString(b.text))
+	if b.href != "" {
+		s.printf(`</a>`)
+	}
+}
+
+// skip is an empty track.
+type skip struct{}
+
+func (skip) size() (int, int, int) { return 0, 0, 0 }
+
+func (skip) draw(*svg, int, int) {}
+
+// sequence draws its elements after each other.
+type sequence []element
+
+func (seq sequence) size() (int, int, int) {
+	var width, up, down int
+	for i, e := range seq {
+		w, u, d := e.size()
+		if i != 0 {
+			width += gap
+		}
+		width += w
+		up, down = max(up, u), max(down, d)
+	}
+	return width, up, down
+}
+
+func (seq sequence) draw(s *svg, x, y int) {
+	for i, e := range seq {
+		if i != 0 {
+			s.line(x, y, gap)
+			x += gap
+		}
+		w, _, _ := e.size()
+		e.draw(s, x, y)
+		x += w
+	}
+}
+
+// choice draws its elements below each other. The first one is drawn on the
+// track, the others branch off.
+type choice []element
+
+// offsets returns the distance between the track and the track of every
+// alternative.
+func (c choice) offsets() []int {
+	offsets := make([]int, len(c))
+	var y, down int
+	for i, e := range c {
+		_, u, d := e.size()
+		if i != 0 {
+			y += max(down+gap+u, 2*radius)
+		}
+		offsets[i] = y
+		down = d
+	}
+	return offsets
+}
+
+func (c choice) size() (int, int, int) {
+	var width int
+	for _, e := range c {
+		w, _, _ := e.size()
+		width = max(width, w)
+	}
+	_, up, _ := c[0].size()
+	offsets := c.offsets()
+	_, _, down := c[len(c)-1].size()
+	return width + 4*radius, up, offsets[len(c)-1] + down
+}
+
+func (c choice) draw(s *svg, x, y int) {
+	width, _, _ := c.size()
+	inner := width - 4*radius
+	for i, e := range c {
+		offset := c.offsets()[i]
+		w, _, _ := e.size()
+		if i == 0 {
+			s.line(x, y, 2*radius)
+		} else {
+			// Branch off on the left...
+			s.path("M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 0 %d %d",
+				x, y, radius, radius, radius, radius, offset-2*radius, radius, radius, radius, radius)
+		}
+		e.draw(s, x+2*radius, y+offset)
+		s.line(x+2*radius+w, y+offset, inner-w)
+		if i == 0 {
+			s.line(x+width-2*radius, y, 2*radius)
+		} else {
+			// ...and join again on the right.
+			s.path("M%d %d a%d %d 0 0 0 %d %d v%d a%d %d 0 0 1 %d %d",
+				x+width-2*radius, y+offset, radius, radius, radius, -radius, -(offset - 2*radius), radius, radius, radius, -radius)
+		}
+	}
+}
+
+// loop draws its element on the track, with a track below it that returns to
+// the start of the element.
+type loop struct {
+	element element
+	// label describes the number of repetitions, if any.
+	label string
+}
+
+// offset returns the distance between the track and the returning track.
+func (l loop) offset() int {
+	_, _, down := l.element.size()
+	return max(down+gap, 2*radius)
+}
+
+func (l loop) size() (int, int, int) {
+	w, up, _ := l.element.size()
+	down := l.offset()
+	if l.label != "" {
+		w = max(w, utf8.RuneCountInString(l.label)*charWidth)
+		down += labelHeight
+	}
+	return w + 2*radius, up, down
+}
+
+func (l loop) draw(s *svg, x, y int) {
+	width, _, _ := l.size()
+	w, _, _ := l.element.size()
+	inner := width - 2*radius
+	offset := l.offset()
+	s.line(x, y, radius)
+	l.element.draw(s, x+radius, y)
+	s.line(x+radius+w, y, width-radius-w)
+	// Clockwise from the end of the element back to its start.
+	s.path("M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d h%d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d",
+		x+width-radius, y, radius, radius, radius, radius, offset-2*radius, radius, radius, -radius, radius,
+		-(inner), radius, radius, -radius, -radius, -(offset - 2*radius), radius, radius, radius, -radius)
+	if l.label != "" {
+		s.printf(`<text class="label" x="%d" y="%d">%s</text>`, x+width/2, y+offset+labelHeight-2, html.EscapeString(l.label))
+	}
+}
+
+// group draws a (dashed) box with a label around its element, e.g. a
+// predicate or a capture.
+type group struct {
+	element element
+	label   string
+	// class is the CSS class of the box: predicate or capture.
+	class string
+}
+
+func (g group) size() (int, int, int) {
+	w, up, down := g.element.size()
+	w = max(w, utf8.RuneCountInString(g.label)*charWidth)
+	return w + 2*padding, up + padding + labelHeight, down + padding
+}
+
+func (g group) draw(s *svg, x, y int) {
+	width, up, down := g.size()
+	w, _, _ := g.element.size()
+	s.printf(`<rect class="%s" x="%d" y="%d" width="%d" height="%d" rx="%d"/>`, g.class, x, y-up, width, up+down, radius/2)
+	s.printf(`<text class="label" x="%d" y="%d">%s</text>`, x+width/2, y-up+labelHeight-2, html.EscapeString(g.label))
+	s.line(x, y, padding)
+	g.element.draw(s, x+padding, y)
+	s.line(x+padding+w, y, width-padding-w)
+}
+
+// svg is the buffer the elements are drawn to.
+type svg struct {
+	strings.Builder
+}
+
+func (s *svg) printf(format string, a ...interface{}) {
+	fmt.Fprintf(s, format, a...)
+	s.WriteByte('\n')
+}
+
+func (s *svg) path(format string, a ...interface{}) {
+	s.printf(`<path d="%s"/>`, fmt.Sprintf(format, a...))
+}
+
+// line draws a horizontal line of the given width.
+func (s *svg) line(x, y, width int) {
+	if width <= 0 {
+		return
+	}
+	s.path("M%d %d h%d", x, y, width)
+}
+
+func max(a, b int) int {
+	if a < b {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,282 @@
+// Package railroad generates railroad (syntax) diagrams of grammars. Every rule
+// is drawn as a standalone SVG image, an HTML index page links them together.
+//
+// Sequences (op.And) are drawn after each other, alternatives (op.Or) below
+// each other and repetitions (op.Range) as loops, annotated with the number of
+// repetitions. Predicates (op.Not and op.Ensure) and captures within a rule are
+// drawn as labeled boxes around their value. Terminals are labeled with their
+// PEGN expression.
+package railroad
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"github.com/di-wu/parser/pegn"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// WriteFiles writes a diagram of every rule to the given directory, named after
+// the rule (e.g. Expr.svg), and an index page (index.html) that shows them all.
+func WriteFiles(dir string, rules []*grammar.Rule) error {
+	for _, r := range rules {
+		if err := checkName(r.Name); err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := WriteSVG(&b, rules, r.Name); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, r.Name+".svg"), b.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	if err := WriteIndex(&b, rules); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "index.html"), b.Bytes(), 0644)
+}
+
+// WriteSVG writes the diagram of the rule with the given name as an SVG image.
+// References to the other rules link to their diagrams (e.g. Expr.svg).
+func WriteSVG(w io.Writer, rules []*grammar.Rule, name string) error {
+	d := diagram{
+		defined: make(map[string]bool),
+	}
+	var rule *grammar.Rule
+	for _, r := range rules {
+		d.defined[r.Name] = true
+		if r.Name == name {
+			rule = r
+		}
+	}
+	if rule == nil {
+		return fmt.Errorf("railroad: undefined rule %s", name)
+	}
+	value := rule.Value
+	if c, ok := value.(ast.Capture); ok {
+		value = c.Value
+	}
+	e, err := d.element(value)
+	if err != nil {
+		return fmt.Errorf("railroad: rule %s: %v", name, err)
+	}
+
+	// The track starts and ends with a short vertical bar.
+	const margin = 20
+	width, up, down := e.size()
+	up = max(up, labelHeight+padding)
+	var s svg
+	s.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width+2*margin+2*gap, up+down+2*margin, width+2*margin+2*gap, up+down+2*margin)
+	s.printf(`<title>%s</title>`, html.EscapeString(name))
+	s.printf(`<style>%s</style>`, style)
+	x, y := margin, margin+up
+	s.path("M%d %d v%d", x, y-radius/2, radius)
+	s.line(x, y, gap)
+	e.draw(&s, x+gap, y)
+	s.line(x+gap+width, y, gap)
+	s.path("M%d %d v%d", x+2*gap+width, y-radius/2, radius)
+	s.printf(`</svg>`)
+	_, err = io.WriteString(w, s.String())
+	return err
+}
+
+// WriteIndex writes an HTML page that shows the diagrams of all the rules, as
+// written by WriteFiles.
+func WriteIndex(w io.Writer, rules []*grammar.Rule) error {
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Grammar</title>\n</head>\n<body>\n<ul>\n")
+	for _, r := range rules {
+		name := html.EscapeString(r.Name)
+		fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a></li>\n", name, name)
+	}
+	b.WriteString("</ul>\n")
+	for _, r := range rules {
+		name := html.EscapeString(r.Name)
+		fmt.Fprintf(&b, "<h2 id=\"%s\">%s</h2>\n<a href=\"%s.svg\"><img src=\"%s.svg\" alt=\"%s\"></a>\n", name, name, name, name, name)
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// style is the stylesheet of the diagrams.
+const style = `path{fill:none;stroke:#333;stroke-width:1.5}` +
+	`rect{stroke:#333;stroke-width:1.5}` +
+	`rect.terminal{fill:#ffd}` +
+	`rect.nonterminal{fill:#def}` +
+	`rect.special{fill:#eee}` +
+	`rect.predicate{fill:none;stroke-dasharray:4 2}` +
+	`rect.capture{fill:none;stroke-dasharray:1 2}` +
+	`text{font:13px monospace;text-anchor:middle}` +
+	`text.label{font-size:11px}`
+
+// checkName returns an error if the name can not be used as file name.
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("railroad: invalid rule name %q", name)
+	}
+	return nil
+}
+
+type diagram struct {
+	// defined contains the names of all the rules.
+	defined map[string]bool
+}
+
+// element converts the given value to the element that draws it.
+func (d diagram) element(i interface{}) (element, error) {
+	switch v := i.(type) {
+	case []interface{}:
+		return d.element(op.And(v))
+	case op.And:
+		if len(v) == 0 {
+			return skip{}, nil
+		}
+		if len(v) == 1 {
+			return d.element(v[0])
+		}
+		return d.elements(v, func(es []element) element { return sequence(es) })
+	case op.Or:
+		if len(v) == 1 {
+			return d.element(v[0])
+		}
+		if isClass(v) {
+			return d.terminal(v)
+		}
+		return d.elements(v, func(es []element) element { return choice(es) })
+	case op.XOr:
+		if len(v) == 1 {
+			return d.element(v[0])
+		}
+		return d.elements(v, func(es []element) element { return choice(es) })
+	case op.Range:
+		return d.loop(v)
+	case op.Not:
+		e, err := d.element(v.Value)
+		return group{element: e, label: "not followed by", class: "predicate"}, err
+	case op.Ensure:
+		e, err := d.element(v.Value)
+		return group{element: e, label: "followed by", class: "predicate"}, err
+	case op.Token:
+		return d.element(v.Value)
+	case op.Expect:
+		return d.element(v.Value)
+	case op.Named:
+		e, err := d.element(v.Value)
+		return group{element: e, label: v.Name, class: "capture"}, err
+	case parser.UnicodeClass, *unicode.RangeTable, op.Quoted:
+		// Can not be expressed in PEGN.
+		return box{text: parser.Stringer(v), class: "terminal"}, nil
+	case op.BackRef:
+		return box{text: "= " + v.Name, class: "special"}, nil
+	case op.Cut:
+		return skip{}, nil
+	case op.Indent:
+		return box{text: "indent", class: "special"}, nil
+	case op.Dedent:
+		return box{text: "dedent", class: "special"}, nil
+	case op.SameIndent:
+		return box{text: "same indent", class: "special"}, nil
+	case grammar.Ref:
+		return d.reference(v.Name), nil
+	case grammar.Original:
+		// The value of an overridden rule is drawn within the new rule.
+		e, err := d.element(v.Rule.Value)
+		return group{element: e, label: v.Rule.Name, class: "capture"}, err
+	case ast.LoopUp:
+		return d.reference(v.Key), nil
+	case ast.Capture:
+		e, err := d.element(v.Value)
+		return group{element: e, label: v.String(), class: "capture"}, err
+	case func(p *ast.Parser) (*ast.Node, error), ast.ParseNode:
+		if name, ok := ast.RuleName(v); ok {
+			return d.reference(name), nil
+		}
+	}
+	return d.terminal(i)
+}
+
+func (d diagram) elements(values []interface{}, f func([]element) element) (element, error) {
+	es := make([]element, len(values))
+	for i, v := range values {
+		e, err := d.element(v)
+		if err != nil {
+			return nil, err
+		}
+		es[i] = e
+	}
+	return f(es), nil
+}
+
+// loop returns the element of the range. Optional values are drawn as an
+// alternative to an empty track.
+func (d diagram) loop(r op.Range) (element, error) {
+	e, err := d.element(r.Value)
+	if err != nil {
+		return nil, err
+	}
+	min := r.Min
+	if min < 0 {
+		min = 0
+	}
+	if r.Max != 1 {
+		var label string
+		switch {
+		case r.Max == -1 && 1 < min:
+			label = fmt.Sprintf("%d or more", min)
+		case r.Max == -1:
+			// Drawn as (optional) loop without annotation.
+		case r.Max <= min:
+			label = fmt.Sprintf("%d times", min)
+		default:
+			label = fmt.Sprintf("%d to %d times", max(min, 1), r.Max)
+		}
+		e = loop{element: e, label: label}
+	}
+	if min == 0 {
+		return choice{e, skip{}}, nil
+	}
+	return e, nil
+}
+
+// reference returns a non-terminal, that links to the diagram of the rule if
+// it is defined.
+func (d diagram) reference(name string) element {
+	b := box{text: name, class: "nonterminal"}
+	if d.defined[name] && checkName(name) == nil {
+		b.href = name + ".svg"
+	}
+	return b
+}
+
+// terminal returns a terminal, labeled with the PEGN expression of the value.
+func (d diagram) terminal(i interface{}) (element, error) {
+	s, err := pegn.Sprint(i)
+	if err != nil {
+		return nil, fmt.Errorf("can not draw %T %s", i, parser.Stringer(i))
+	}
+	return box{text: s, class: "terminal"}, nil
+}
+
+// isClass returns whether all the alternatives are (ranges of) runes.
+func isClass(or op.Or) bool {
+	for _, v := range or {
+		switch v.(type) {
+		case rune, int, parser.RuneClass, parser.RuneRangeClass:
+		default:
+			return false
+		}
+	}
+	return true
+}
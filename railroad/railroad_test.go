@@ -0,0 +1,124 @@
+package railroad_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"github.com/di-wu/parser/pegn"
+	"github.com/di-wu/parser/railroad"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ExampleWriteFiles() {
+	g, _ := pegn.Compile([]byte(`
+List    <-- '[' Integer (',' SP? Integer)* ']'
+Integer <-- '-'? digit{1,3}
+`))
+	dir, _ := ioutil.TempDir("", "railroad")
+	defer os.RemoveAll(dir)
+	if err := railroad.WriteFiles(dir, g.Rules()); err != nil {
+		fmt.Println(err)
+		return
+	}
+	files, _ := ioutil.ReadDir(dir)
+	for _, f := range files {
+		fmt.Println(f.Name())
+	}
+	// Output:
+	// Integer.svg
+	// List.svg
+	// index.html
+}
+
+func ExampleWriteSVG_unsupported() {
+	err := railroad.WriteSVG(ioutil.Discard, []*grammar.Rule{
+		{Name: "Vowel", Value: parser.CheckRuneFunc(func(r rune) bool {
+			return strings.ContainsRune("aeiou", r)
+		})},
+	}, "Vowel")
+	fmt.Println(err)
+	// Output:
+	// railroad: rule Vowel: can not draw parser.AnonymousClass func
+}
+
+// TestWriteSVG checks whether the diagrams of all the PEGN grammars are valid
+// XML documents.
+func TestWriteSVG(t *testing.T) {
+	files, _ := filepath.Glob("../examples/*/*.pegn")
+	files = append(files, "../ast/grammar.pegn", "../pegn/grammar.pegn")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := pegn.Compile(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range g.Rules() {
+			var b bytes.Buffer
+			if err := railroad.WriteSVG(&b, g.Rules(), r.Name); err != nil {
+				t.Errorf("%s: %v", file, err)
+				continue
+			}
+			if err := validate(&b); err != nil {
+				t.Errorf("%s: %s: %v", file, r.Name, err)
+			}
+		}
+	}
+}
+
+func TestWriteSVG_elements(t *testing.T) {
+	rules := []*grammar.Rule{
+		{Name: "Block", Value: op.And{
+			op.Ensure{Value: grammar.Ref{Name: "Line"}},
+			op.MinMax(2, 5, grammar.Ref{Name: "Line"}),
+			op.Not{Value: parser.EOD},
+		}},
+		{Name: "Line", Value: op.And{op.MinZero(' '), op.Optional("text"), '\n'}},
+	}
+	var b bytes.Buffer
+	if err := railroad.WriteSVG(&b, rules, "Block"); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+	for _, s := range []string{
+		`<a href="Line.svg">`,
+		`>followed by</text>`,
+		`>not followed by</text>`,
+		`>2 to 5 times</text>`,
+		`>EOD</text>`,
+	} {
+		if !strings.Contains(svg, s) {
+			t.Errorf("missing %s", s)
+		}
+	}
+	if err := validate(&b); err != nil {
+		t.Error(err)
+	}
+
+	if err := railroad.WriteSVG(&b, rules, "Word"); err == nil {
+		t.Error("expected an error for an undefined rule")
+	}
+}
+
+// validate returns an error if the document is not valid XML.
+func validate(r io.Reader) error {
+	d := xml.NewDecoder(r)
+	for {
+		if _, err := d.Token(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
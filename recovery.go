@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"errors"
+	"github.com/di-wu/parser/op"
+)
+
+// SetRecovery registers a value to recover from the failure of an op.Expect
+// with the given label. e.g. a value that skips to the end of the statement.
+//
+// The recovery value gets matched at the position at which the op.Expect
+// started. If it matches, then the error gets recorded (see Recovered) and the
+// parser continues as if the op.Expect matched.
+func (p *Parser) SetRecovery(label string, i interface{}) {
+	if p.recovery == nil {
+		p.recovery = make(map[string]interface{})
+	}
+	p.recovery[label] = i
+}
+
+// Recovery returns the recovery value registered for the given label.
+func (p *Parser) Recovery(label string) (interface{}, bool) {
+	i, ok := p.recovery[label]
+	return i, ok
+}
+
+// Recovered returns the errors the parser recovered from, in the order they
+// occurred. Errors within values the parser backtracked out of are discarded.
+func (p *Parser) Recovered() []*ExpectedParseError {
+	list, _ := p.Value(recoveredKey{}).(*recovered)
+	return list.errors()
+}
+
+// expectLabeled handles the failure of an op.Expect, see SetRecovery.
+func (p *Parser) expectLabeled(v op.Expect, start *Cursor, err error) (*Cursor, error) {
+	if isLabeled(err) {
+		// Labeled by a nested op.Expect.
+		return nil, err
+	}
+	labeled := p.LabeledError(v, start, err)
+	if r, ok := p.Recovery(v.Label); ok {
+		if last, err := p.Expect(r); err == nil {
+			p.AddRecovered(labeled)
+			return last, nil
+		}
+	}
+	return nil, labeled
+}
+
+// LabeledError creates an ExpectedParseError with the label of the op.Expect,
+// based on the error of its value. Resets the parser to the start cursor.
+func (p *Parser) LabeledError(v op.Expect, start *Cursor, err error) *ExpectedParseError {
+	var (
+		labeled ExpectedParseError
+		e       *ExpectedParseError
+	)
+	if errors.As(err, &e) {
+		labeled = *e
+	} else {
+		labeled = *p.ExpectedParseError(v.Value, start, start)
+	}
+	labeled.Label = v.Label
+	p.Jump(start)
+	return &labeled
+}
+
+// AddRecovered records an error the parser recovered from. This allows parsers
+// that are built on top of the parser (e.g. the ast parser) to recover.
+func (p *Parser) AddRecovered(err *ExpectedParseError) {
+	list, _ := p.Value(recoveredKey{}).(*recovered)
+	p.SetValue(recoveredKey{}, &recovered{
+		err:    err,
+		parent: list,
+	})
+}
+
+// recoveredKey is the key of the recovered errors within the values.
+type recoveredKey struct{}
+
+// recovered is an immutable list of errors, the last error comes first.
+type recovered struct {
+	err    *ExpectedParseError
+	parent *recovered
+}
+
+func (r *recovered) errors() []*ExpectedParseError {
+	var errs []*ExpectedParseError
+	for ; r != nil; r = r.parent {
+		errs = append([]*ExpectedParseError{r.err}, errs...)
+	}
+	return errs
+}
@@ -0,0 +1,39 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// SetSkipper sets a value that gets skipped (zero or more times) before every
+// token: runes, strings, op.Quoted and op.Token values. e.g. whitespace and
+// comments. This way these do not need to be part of the grammar.
+//
+// Nothing gets skipped within op.Token values and classes, those are lexical.
+// The skipper should always consume data if it matches.
+func (p *Parser) SetSkipper(i interface{}) {
+	p.skipper = i
+}
+
+// expectToken skips the values of the skipper and checks whether the buffer
+// contains the given token. Resets the parser if it does not.
+func (p *Parser) expectToken(i interface{}) (*Cursor, error) {
+	start := p.Mark()
+	p.lexical++
+	defer func() { p.lexical-- }()
+
+	_, _ = p.Expect(op.MinZero(p.skipper))
+	mark, err := p.Expect(i)
+	if err != nil {
+		p.Jump(start)
+		return nil, err
+	}
+	return mark, nil
+}
+
+// isToken checks whether the skipper gets applied before the given value.
+func isToken(i interface{}) bool {
+	switch i.(type) {
+	case rune, string, op.Quoted, op.Token:
+		return true
+	default:
+		return false
+	}
+}
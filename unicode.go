@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/op"
+	"sort"
+	"sync"
+	"unicode"
+)
+
+// CheckCategory returns a class that checks whether the current rune of the
+// parser belongs to the given Unicode category, e.g. "Lu" (upper case letters)
+// or "L" (all letters). See unicode.Categories for all the categories.
+func CheckCategory(name string) UnicodeClass {
+	return UnicodeClass{
+		Name:  name,
+		Table: unicode.Categories[name],
+	}
+}
+
+// CheckScript returns a class that checks whether the current rune of the
+// parser belongs to the given Unicode script, e.g. "Greek" or "Han". See
+// unicode.Scripts for all the scripts.
+func CheckScript(name string) UnicodeClass {
+	return UnicodeClass{
+		Name:  name,
+		Table: unicode.Scripts[name],
+	}
+}
+
+// CheckProperty returns a class that checks whether the current rune of the
+// parser has the given Unicode property, e.g. "White_Space". See
+// unicode.Properties for all the properties.
+func CheckProperty(name string) UnicodeClass {
+	return UnicodeClass{
+		Name:  name,
+		Table: unicode.Properties[name],
+	}
+}
+
+// UnicodeClass is a class that matches the runes of a Unicode category, script
+// or property, see CheckCategory, CheckScript and CheckProperty. The parser
+// returns an error if the table is nil (e.g. the name is unknown).
+type UnicodeClass struct {
+	// Name of the category, script or property.
+	Name  string
+	Table *unicode.RangeTable
+}
+
+// Check checks whether the current rune of the parser is in the table.
+func (c UnicodeClass) Check(p *Parser) (*Cursor, bool) {
+	return p.Mark(), c.Table != nil && unicode.Is(c.Table, p.Current())
+}
+
+// String returns the class as a regular expression, e.g. \p{Greek}.
+func (c UnicodeClass) String() string {
+	return fmt.Sprintf("\\p{%s}", c.Name)
+}
+
+// CharSetOfTable returns a set that contains the runes of the given table.
+func CharSetOfTable(t *unicode.RangeTable) CharSet {
+	var ranges []RuneRangeClass
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, RuneRangeClass{Min: lo, Max: hi})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			ranges = append(ranges, RuneRangeClass{Min: r, Max: r})
+		}
+	}
+	for _, r := range t.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range t.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return newCharSet(ranges)
+}
+
+// Identifiers as defined by UAX #31 (Unicode Identifiers and Syntax). The sets
+// are built the first time they are used.
+var identifiers struct {
+	once                                       sync.Once
+	idStart, idContinue, xidStart, xidContinue CharSet
+}
+
+// buildIdentifiers builds the identifier sets, only once.
+func buildIdentifiers() {
+	identifiers.once.Do(func() {
+		pattern := unionOfTables(unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+		identifiers.idStart = unionOfTables(unicode.L, unicode.Nl, unicode.Other_ID_Start).
+			Difference(pattern)
+		identifiers.idContinue = identifiers.idStart.
+			Union(unionOfTables(unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)).
+			Difference(pattern)
+		identifiers.xidStart = identifiers.idStart.Difference(CharSetOf(
+			0x037A, 0x0E33, 0x0EB3, 0x309B, 0x309C, 0xFC5E, 0xFC5F, 0xFC60, 0xFC61,
+			0xFC62, 0xFC63, 0xFDFA, 0xFDFB, 0xFE70, 0xFE72, 0xFE74, 0xFE76, 0xFE78,
+			0xFE7A, 0xFE7C, 0xFE7E, 0xFF9E, 0xFF9F,
+		))
+		identifiers.xidContinue = identifiers.idContinue.Difference(CharSetOf(
+			0x037A, 0x309B, 0x309C, 0xFC5E, 0xFC5F, 0xFC60, 0xFC61, 0xFC62, 0xFC63,
+			0xFDFA, 0xFDFB, 0xFE70, 0xFE72, 0xFE74, 0xFE76, 0xFE78, 0xFE7A, 0xFE7C,
+			0xFE7E,
+		))
+	})
+}
+
+// IDStart returns the runes that can start an identifier: letters, letter
+// numbers and the runes with the Other_ID_Start property, except for pattern
+// syntax and white space.
+func IDStart() CharSet {
+	buildIdentifiers()
+	return identifiers.idStart
+}
+
+// IDContinue returns the runes that can continue an identifier: IDStart,
+// marks, digits, connector punctuation and the runes with the
+// Other_ID_Continue property, except for pattern syntax and white space.
+func IDContinue() CharSet {
+	buildIdentifiers()
+	return identifiers.idContinue
+}
+
+// XIDStart returns IDStart, closed under NFKC normalization.
+func XIDStart() CharSet {
+	buildIdentifiers()
+	return identifiers.xidStart
+}
+
+// XIDContinue returns IDContinue, closed under NFKC normalization.
+func XIDContinue() CharSet {
+	buildIdentifiers()
+	return identifiers.xidContinue
+}
+
+// Identifier returns a value that matches an identifier following the default
+// identifier syntax of UAX #31: XIDStart XIDContinue*. Profiles can be created
+// by extending the sets, e.g. to allow identifiers to start with an underscore:
+//
+//	op.And{parser.XIDStart().Union(parser.CharSetOf('_')), op.MinZero(parser.XIDContinue())}
+func Identifier() op.And {
+	return op.And{XIDStart(), op.MinZero(XIDContinue())}
+}
+
+func unionOfTables(tables ...*unicode.RangeTable) CharSet {
+	var cs CharSet
+	for _, t := range tables {
+		cs = cs.Union(CharSetOfTable(t))
+	}
+	return cs
+}
+
+// tableName returns the name of the category, script or property of the given
+// table. If the table has multiple names, the first one (sorted) is returned.
+func tableName(t *unicode.RangeTable) (string, bool) {
+	for _, tables := range []map[string]*unicode.RangeTable{
+		unicode.Categories, unicode.Scripts, unicode.Properties,
+	} {
+		var names []string
+		for name, table := range tables {
+			if table == t {
+				names = append(names, name)
+			}
+		}
+		if len(names) != 0 {
+			sort.Strings(names)
+			return names[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+	"unicode"
+)
+
+func ExampleCheckCategory() {
+	p, _ := parser.New([]byte("Ab"))
+	upper := parser.CheckCategory("Lu")
+	fmt.Println(p.Expect(upper))
+	fmt.Println(p.Expect(upper))
+	fmt.Println(p.Expect(parser.CheckCategory("Xx")))
+	// Output:
+	// U+0041: A <nil>
+	// <nil> parse conflict [00:001]: expected parser.UnicodeClass \p{Lu} but got 'b'
+	// <nil> expect: unknown unicode class Xx
+}
+
+func ExampleCheckScript() {
+	p, _ := parser.New([]byte("αβγ 汉字"))
+	start := p.Mark()
+	end, _ := p.Expect(op.MinOne(parser.CheckScript("Greek")))
+	fmt.Println(p.Slice(start, end))
+	fmt.Println(p.Expect(op.And{' ', unicode.Han, unicode.Han}))
+	fmt.Println(p.Expect(unicode.Han))
+	// Output:
+	// αβγ
+	// U+5B57: 字 <nil>
+	// <nil> parse conflict [00:013]: expected *unicode.RangeTable \p{Han} but got ""
+}
+
+func ExampleIdentifier() {
+	p, _ := parser.New([]byte("naïve_变量1 1st"))
+	start := p.Mark()
+	end, _ := p.Expect(parser.Identifier())
+	fmt.Println(p.Slice(start, end))
+	fmt.Println(parser.XIDStart().Contains('1'), parser.XIDContinue().Contains('1'))
+	// Output:
+	// naïve_变量1
+	// false true
+}
+
+// TestXID compares the identifier sets with the ASCII identifiers of UAX #31:
+// [A-Za-z][0-9A-Z_a-z]*.
+func TestXID(t *testing.T) {
+	for r := rune(0); r < 128; r++ {
+		start := unicode.IsLetter(r)
+		if parser.XIDStart().Contains(r) != start || parser.IDStart().Contains(r) != start {
+			t.Errorf("start %q: expected %v", r, start)
+		}
+		cont := start || unicode.IsDigit(r) || r == '_'
+		if parser.XIDContinue().Contains(r) != cont || parser.IDContinue().Contains(r) != cont {
+			t.Errorf("continue %q: expected %v", r, cont)
+		}
+	}
+	for _, r := range []rune{'é', 'ß', 'Ω', '変', 'ⅳ'} {
+		if !parser.XIDStart().Contains(r) {
+			t.Errorf("start %q: expected true", r)
+		}
+	}
+	for _, r := range []rune{'\u0301', '٣', '‿'} {
+		if parser.XIDStart().Contains(r) || !parser.XIDContinue().Contains(r) {
+			t.Errorf("%q: expected continue only", r)
+		}
+	}
+	// Other_ID_Start.
+	for _, r := range []rune{'℘', '℮', '゛'} {
+		if !parser.IDStart().Contains(r) {
+			t.Errorf("%q: expected Other_ID_Start", r)
+		}
+	}
+	// Not closed under NFKC normalization.
+	for _, r := range []rune{0x037A, '゛'} {
+		if parser.XIDStart().Contains(r) || parser.XIDContinue().Contains(r) {
+			t.Errorf("%q: expected to be excluded from XID", r)
+		}
+	}
+}
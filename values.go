@@ -0,0 +1,34 @@
+package parser
+
+// values is a list of key/value pairs set by the user. It is immutable so
+// cursors can share it, this way Jump also discards the values that were set
+// after the mark was created.
+type values struct {
+	key, value interface{}
+	parent     *values
+}
+
+// SetValue stores the value under the given key. This allows rules to keep
+// track of context, e.g. declared type names or the terminator of a heredoc.
+//
+// The value is stored in the cursor of the parser, so it gets discarded if the
+// parser backtracks (jumps) to a mark from before the value was set. Values
+// should therefore not be modified after they are set, set a new value instead.
+func (p *Parser) SetValue(key, value interface{}) {
+	p.cursor.values = &values{
+		key:    key,
+		value:  value,
+		parent: p.cursor.values,
+	}
+}
+
+// Value returns the value that is stored under the given key, nil if there is
+// none.
+func (p *Parser) Value(key interface{}) interface{} {
+	for v := p.cursor.values; v != nil; v = v.parent {
+		if v.key == key {
+			return v.value
+		}
+	}
+	return nil
+}